@@ -28,6 +28,20 @@ type Box interface {
 	Draw(page *builder.Builder, xPos, yPos float64)
 }
 
+// parentBox is implemented by boxes which contain other boxes.
+type parentBox interface {
+	Box
+	children() []Box
+}
+
+// boxChildren returns the boxes contained in box.
+func boxChildren(box Box) []Box {
+	if p, ok := box.(parentBox); ok {
+		return p.children()
+	}
+	return nil
+}
+
 // BoxExtent gives the dimensions of a Box.
 type BoxExtent struct {
 	Width, Height, Depth float64
@@ -114,6 +128,99 @@ func (obj raiseBox) Draw(page *builder.Builder, xPos, yPos float64) {
 	obj.box.Draw(page, xPos, yPos+obj.delta)
 }
 
+func (obj raiseBox) children() []Box {
+	return []Box{obj.box}
+}
+
+// VCenter raises or lowers the box so that its vertical centre is at the
+// given height above the baseline.  For example, axis can be the height of
+// the maths axis of a font, to centre an inline box on the axis.
+func VCenter(axis float64, box Box) Box {
+	ext := box.Extent()
+	return Raise(axis-(ext.Height-ext.Depth)/2, box)
+}
+
+// LLap returns a box of width zero, which shows the given box to the left
+// of the reference point.  This can be used to place material, for example
+// line numbers, into the left margin.
+func LLap(box Box) Box {
+	return lapBox{box: box, shift: 1}
+}
+
+// RLap returns a box of width zero, which shows the given box to the right
+// of the reference point.
+func RLap(box Box) Box {
+	return lapBox{box: box, shift: 0}
+}
+
+// CLap returns a box of width zero, which shows the given box centred on
+// the reference point.
+func CLap(box Box) Box {
+	return lapBox{box: box, shift: 0.5}
+}
+
+type lapBox struct {
+	box   Box
+	shift float64 // fraction of the width to the left of the reference point
+}
+
+func (obj lapBox) Extent() *BoxExtent {
+	ext := obj.box.Extent()
+	return &BoxExtent{
+		Height:         ext.Height,
+		Depth:          ext.Depth,
+		WhiteSpaceOnly: ext.WhiteSpaceOnly,
+	}
+}
+
+// name returns the name of the function which created the box.
+func (obj lapBox) name() string {
+	switch obj.shift {
+	case 0:
+		return "rlap"
+	case 1:
+		return "llap"
+	default:
+		return "clap"
+	}
+}
+
+func (obj lapBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	width := obj.box.Extent().Width
+	obj.box.Draw(page, xPos-obj.shift*width, yPos)
+}
+
+func (obj lapBox) children() []Box {
+	return []Box{obj.box}
+}
+
+// Smash returns a box with the width of the given box, but with height and
+// depth zero.  This can be used to stop tall material from increasing the
+// distance between lines.
+func Smash(box Box) Box {
+	return smashBox{box: box}
+}
+
+type smashBox struct {
+	box Box
+}
+
+func (obj smashBox) Extent() *BoxExtent {
+	ext := obj.box.Extent()
+	return &BoxExtent{
+		Width:          ext.Width,
+		WhiteSpaceOnly: ext.WhiteSpaceOnly,
+	}
+}
+
+func (obj smashBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.box.Draw(page, xPos, yPos)
+}
+
+func (obj smashBox) children() []Box {
+	return []Box{obj.box}
+}
+
 // HBox creates a new HBox
 func HBox(children ...Box) Box {
 	res := &hBox{
@@ -145,6 +252,7 @@ func HBoxTo(width float64, contents ...Box) Box {
 			Width: width,
 		},
 		Contents: contents,
+		overfull: isOverfull(width, contents),
 	}
 	first := true
 	for _, box := range contents {
@@ -169,44 +277,43 @@ type hBox struct {
 	BoxExtent
 
 	Contents []Box
+
+	// overfull is set if the contents do not fit into the width of the
+	// box, even with all glue shrunk as far as possible.
+	overfull bool
 }
 
 // Draw implements the [Box] interface.
 func (obj *hBox) Draw(page *builder.Builder, xPos, yPos float64) {
 	xx := horizontalLayout(xPos, obj.Width, obj.Contents...)
+	xx = append(xx, xPos+obj.Width)
 	for i, box := range obj.Contents {
+		if g, ok := box.(*Glue); ok && g.leader != nil {
+			g.drawLeaders(page, xx[i], yPos, xx[i+1]-xx[i])
+			continue
+		}
 		box.Draw(page, xx[i], yPos)
 	}
 }
 
+func (obj *hBox) children() []Box {
+	return obj.Contents
+}
+
+// isOverfull reports whether the given boxes are wider than width, even
+// with all glue shrunk as far as possible.
+func isOverfull(width float64, boxes []Box) bool {
+	total := totalWidthAndGlue(boxes)
+	return total.Shrink.Order == 0 && total.Length-total.Shrink.Val > width+eps
+}
+
 func horizontalLayout(xLeft, width float64, boxes ...Box) []float64 {
+	gs := newGlueSet(totalWidthAndGlue(boxes), width)
 	x := xLeft
 	xx := make([]float64, 0, len(boxes))
-	total := totalWidthAndGlue(boxes)
-	if total.Length < width-eps && total.Stretch.Val > 0 {
-		// contents are too narrow, stretch all available glue
-		q := (width - total.Length) / total.Stretch.Val
-		for _, box := range boxes {
-			xx = append(xx, x)
-			x += box.Extent().Width + q*getStretch(box, total.Stretch.Order)
-		}
-	} else if total.Length > width+eps && total.Shrink.Val > 0 {
-		// contents are too wide, shrink all available glue
-		q := (total.Length - width) / total.Shrink.Val
-		if total.Shrink.Order == 0 && q > 1 {
-			// glue can't shrink beyond its minimum width
-			q = 1
-		}
-		for _, box := range boxes {
-			xx = append(xx, x)
-			x += box.Extent().Width - q*getShrink(box, total.Shrink.Order)
-		}
-	} else {
-		// lay out contents at their natural width
-		for _, box := range boxes {
-			xx = append(xx, x)
-			x += box.Extent().Width
-		}
+	for _, box := range boxes {
+		xx = append(xx, x)
+		x += box.Extent().Width + gs.adjust(box)
 	}
 	return xx
 }
@@ -281,45 +388,30 @@ type vBox struct {
 
 func (obj *vBox) Draw(page *builder.Builder, xPos, yPos float64) {
 	yy := verticalLayout(yPos+obj.Height, obj.Height+obj.Depth, obj.Contents...)
+	top := yPos + obj.Height
 	for i, box := range obj.Contents {
-		box.Draw(page, xPos, yy[i])
+		if g, ok := box.(*Glue); ok && g.leader != nil {
+			g.drawVLeaders(page, xPos, yy[i], top-yy[i])
+		} else {
+			box.Draw(page, xPos, yy[i])
+		}
+		top = yy[i] - box.Extent().Depth
 	}
 }
 
+func (obj *vBox) children() []Box {
+	return obj.Contents
+}
+
 func verticalLayout(yTop, height float64, boxes ...Box) []float64 {
+	gs := newGlueSet(totalHeightAndGlue(boxes), height)
 	y := yTop
 	yy := make([]float64, 0, len(boxes))
-	total := totalHeightAndGlue(boxes)
-	if total.Length < height-eps && total.Stretch.Val > 0 {
-		// contents are too short, stretch all available glue
-		q := (height - total.Length) / total.Stretch.Val
-		for _, box := range boxes {
-			ext := box.Extent()
-			y -= ext.Height + q*getStretch(box, total.Stretch.Order)
-			yy = append(yy, y)
-			y -= ext.Depth
-		}
-	} else if total.Length > height+eps && total.Shrink.Val > 0 {
-		// contents are too tall, shrink all available glue
-		q := (total.Length - height) / total.Shrink.Val
-		if total.Shrink.Order == 0 && q > 1 {
-			// glue can't shrink beyond its minimum width
-			q = 1
-		}
-		for _, box := range boxes {
-			ext := box.Extent()
-			y -= ext.Height - q*getShrink(box, total.Shrink.Order)
-			yy = append(yy, y)
-			y -= ext.Depth
-		}
-	} else {
-		// lay out contents at their natural height+depth
-		for _, box := range boxes {
-			ext := box.Extent()
-			y -= ext.Height
-			yy = append(yy, y)
-			y -= ext.Depth
-		}
+	for _, box := range boxes {
+		ext := box.Extent()
+		y -= ext.Height + gs.adjust(box)
+		yy = append(yy, y)
+		y -= ext.Depth
 	}
 	return yy
 }
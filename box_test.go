@@ -16,9 +16,63 @@
 
 package layout
 
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
 // compile-time test: we implement the Box interface
 var _ Box = &ruleBox{}
 var _ Box = &vBox{}
 var _ Box = Kern(0)
 var _ Box = &Glue{}
 var _ Box = &TextBox{}
+
+// posBox records the position where it was drawn.
+type posBox struct {
+	BoxExtent
+	x, y float64
+}
+
+func (obj *posBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.x, obj.y = xPos, yPos
+}
+
+func TestLapAndSmash(t *testing.T) {
+	box := &posBox{BoxExtent: BoxExtent{Width: 10, Height: 8, Depth: 2}}
+	other := &posBox{BoxExtent: BoxExtent{Width: 20, Height: 3, Depth: 1}}
+
+	for _, c := range []struct {
+		lap   Box
+		wantX float64
+	}{
+		{LLap(box), -10},
+		{RLap(box), 0},
+		{CLap(box), -5},
+	} {
+		h := HBox(c.lap, other)
+		ext := h.Extent()
+		if ext.Width != 20 || ext.Height != 8 || ext.Depth != 2 {
+			t.Errorf("wrong extent %v", ext)
+		}
+		h.Draw(nil, 0, 0)
+		if box.x != c.wantX || box.y != 0 {
+			t.Errorf("drawn at (%g, %g), want (%g, 0)", box.x, box.y, c.wantX)
+		}
+	}
+
+	ext := HBox(Smash(box), other).Extent()
+	if ext.Width != 30 || ext.Height != 3 || ext.Depth != 1 {
+		t.Errorf("wrong extent %v", ext)
+	}
+
+	ext = VCenter(3, box).Extent()
+	if ext.Height != 8 || ext.Depth != 2 {
+		t.Errorf("wrong extent %v", ext)
+	}
+	ext = VCenter(0, box).Extent()
+	if ext.Height != 5 || ext.Depth != 5 {
+		t.Errorf("wrong extent %v", ext)
+	}
+}
@@ -0,0 +1,133 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+
+	"seehuhn.de/go/geom/matrix"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// Clip returns a box which shows only the part of box which lies inside
+// its extent.
+func Clip(box Box) Box {
+	return &clipBox{box: box}
+}
+
+// ClipPath returns a box which shows only the part of box which lies
+// inside the path constructed by path.  When path is called, the origin of
+// the coordinate system is at the lower left corner of the box, as for
+// [Graphics].  The function must construct the path without painting it;
+// the nonzero winding number rule is used to determine the inside of the
+// path.
+func ClipPath(box Box, path func(page *builder.Builder)) Box {
+	return &clipBox{box: box, path: path}
+}
+
+type clipBox struct {
+	box  Box
+	path func(page *builder.Builder)
+}
+
+func (obj *clipBox) Extent() *BoxExtent {
+	return obj.box.Extent()
+}
+
+func (obj *clipBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	ext := obj.box.Extent()
+	page.PushGraphicsState()
+	page.Transform(matrix.Translate(xPos, yPos-ext.Depth))
+	if obj.path != nil {
+		obj.path(page)
+	} else {
+		page.Rectangle(0, 0, ext.Width, ext.Height+ext.Depth)
+	}
+	page.ClipNonZero()
+	page.EndPath()
+	obj.box.Draw(page, 0, ext.Depth)
+	page.PopGraphicsState()
+}
+
+func (obj *clipBox) children() []Box {
+	return []Box{obj.box}
+}
+
+// Container is a box of fixed size.  The contents are drawn with their
+// reference point at the reference point of the container, and are clipped
+// to the extent of the container.  Use [Container.Overflow] to check
+// whether the contents fit.
+type Container struct {
+	BoxExtent
+	contents Box
+}
+
+// NewContainer returns a box of the given size, showing the given contents.
+//
+// To show a paragraph of text in a fixed area, set the TextWidth of an
+// [Engine] to the width of the area, add the text, and use the result of
+// [Engine.MakeVTop] as the contents.
+func NewContainer(width, height, depth float64, contents Box) *Container {
+	return &Container{
+		BoxExtent: BoxExtent{Width: width, Height: height, Depth: depth},
+		contents:  contents,
+	}
+}
+
+// Overflow reports whether the contents extend beyond the container, and
+// thus are truncated when the container is drawn.  Overfull lines, whose
+// material is wider than the line even with all glue shrunk, also count as
+// overflow.
+func (c *Container) Overflow() bool {
+	ext := c.contents.Extent()
+	if ext.Width > c.Width+eps ||
+		ext.Height > c.Height+eps ||
+		ext.Depth > c.Depth+eps {
+		return true
+	}
+	return hasOverfullLine(c.contents)
+}
+
+// hasOverfullLine reports whether box contains a horizontal box whose
+// contents do not fit into its width.  Nested containers and clipped boxes
+// are not searched, since their contents are clipped anyway.
+func hasOverfullLine(box Box) bool {
+	switch box := box.(type) {
+	case *Container, *clipBox:
+		return false
+	case *hBox:
+		if box.overfull {
+			return true
+		}
+	}
+	return slices.ContainsFunc(boxChildren(box), hasOverfullLine)
+}
+
+// Draw implements the [Box] interface.
+func (c *Container) Draw(page *builder.Builder, xPos, yPos float64) {
+	page.PushGraphicsState()
+	page.Rectangle(xPos, yPos-c.Depth, c.Width, c.Height+c.Depth)
+	page.ClipNonZero()
+	page.EndPath()
+	c.contents.Draw(page, xPos, yPos)
+	page.PopGraphicsState()
+}
+
+func (c *Container) children() []Box {
+	return []Box{c.contents}
+}
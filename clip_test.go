@@ -0,0 +1,74 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestContainerOverflow(t *testing.T) {
+	fi := testFont(t)
+
+	label := func(text string) *Container {
+		e := &Engine{
+			TextWidth:    100,
+			BaseLineSkip: 12,
+			ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		}
+		e.HAddText(fi, text)
+		e.EndParagraph()
+		return NewContainer(100, 10, 14, e.MakeVTop())
+	}
+
+	if c := label("Short label"); c.Overflow() {
+		t.Error("short text reported as overflowing")
+	}
+	if c := label("A much longer label, which needs several lines and thus does not fit"); !c.Overflow() {
+		t.Error("long text not reported as overflowing")
+	}
+
+	// A single word which is too long for the line gives an overfull
+	// line.  The VTop has the width of the line, so the overflow is only
+	// visible inside the line.
+	if c := label("Pneumonoultramicroscopicsilicovolcanoconiosis"); !c.Overflow() {
+		t.Error("overfull line not reported as overflowing")
+	}
+
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   500,
+		BaseLineSkip: 12,
+	}
+	e.VAddBox(label("Too much text for the space available in the label"))
+	e.VAddBox(Clip(Raise(-5, Rule(50, 10, 0))))
+	e.VAddBox(ClipPath(Rule(50, 10, 0), func(page *builder.Builder) {
+		page.Circle(25, 5, 5)
+	}))
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
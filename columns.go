@@ -0,0 +1,181 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// balanceTolerance is the precision to which balanced column heights are
+// determined.
+const balanceTolerance = 0.1
+
+// SetColumns changes the number of columns used for the following material.
+// Paragraphs which are finished after this call are typeset to the new
+// column width.
+//
+// If the previous column layout ends part way down a page, its columns are
+// balanced and the new layout continues directly below.  When the final page
+// is output, multi-column material on the last page is balanced, too.
+func (e *Engine) SetColumns(n int) {
+	n = max(n, 1)
+	if n == max(e.columns, 1) {
+		return
+	}
+	e.columns = n
+	e.vList = append(e.vList, columnChange(n))
+}
+
+// ColumnWidth returns the width of a single column of text.
+// This is the text width, reduced to accommodate the current number of
+// columns and the ColumnSep between them.  The text width is TextWidth,
+// unless the current page template specifies a different width.
+func (e *Engine) ColumnWidth() float64 {
+	textWidth := e.TextWidth
+	if e.template != nil && e.template.TextWidth > 0 {
+		textWidth = e.template.TextWidth
+	}
+	return e.columnWidth(textWidth, max(e.columns, 1))
+}
+
+func (e *Engine) columnWidth(textWidth float64, n int) float64 {
+	return (textWidth - float64(n-1)*e.ColumnSep) / float64(n)
+}
+
+// columnChange marks a change of the number of columns in the vertical list.
+type columnChange int
+
+func (obj columnChange) Extent() *BoxExtent {
+	return &BoxExtent{WhiteSpaceOnly: true}
+}
+
+func (obj columnChange) Draw(page *builder.Builder, xPos, yPos float64) {
+	// pass
+}
+
+// vConsumeColumnChanges processes column changes at the start of the
+// vertical list.
+func (e *Engine) vConsumeColumnChanges() {
+	for len(e.vList) > 0 {
+		n, ok := e.vList[0].(columnChange)
+		if !ok {
+			break
+		}
+		e.pageColumns = int(n)
+		e.vList = e.vList[1:]
+	}
+}
+
+// vSectionEnd returns the position of the next change of the column layout
+// or of the page template in vList, or len(vList) if there is none.
+func vSectionEnd(vList []Box) int {
+	for i, box := range vList {
+		switch box.(type) {
+		case columnChange, *pageTemplateChange:
+			return i
+		}
+	}
+	return len(vList)
+}
+
+// vFillColumns distributes material from the start of vList into n columns
+// of the given height.  The first baseline of every column is placed skip
+// below the top of the columns.  If keepLeading is set, the first column
+// instead starts directly with the material from vList.  The function
+// returns the contents of the columns, the remaining material, and whether
+// any column is overfull.
+func (e *Engine) vFillColumns(vList []Box, n int, height, skip float64, keepLeading bool) ([][]Box, []Box, bool) {
+	cols := make([][]Box, n)
+	anyOverfull := false
+	for j := range cols {
+		if len(vList) == 0 {
+			continue
+		}
+		s := skip
+		if j == 0 && keepLeading {
+			s = -1
+		}
+		var overfull bool
+		cols[j], vList, overfull = e.vFillColumn(vList, height, s)
+		anyOverfull = anyOverfull || overfull
+	}
+	return cols, vList, anyOverfull
+}
+
+// vBalance returns the smallest column height, up to maxHeight, for which
+// all of vList fits into n columns.  If the material does not fit even at
+// maxHeight, false is returned.
+func (e *Engine) vBalance(vList []Box, n int, maxHeight, skip float64, keepLeading bool) (float64, bool) {
+	fits := func(height float64) bool {
+		_, rest, overfull := e.vFillColumns(vList, n, height, skip, keepLeading)
+		return len(rest) == 0 && !overfull
+	}
+	if !fits(maxHeight) {
+		return 0, false
+	}
+
+	lo, hi := 0.0, maxHeight
+	for hi-lo > balanceTolerance {
+		mid := (lo + hi) / 2
+		if fits(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, true
+}
+
+// vColumnBox arranges the given columns side by side.
+func (e *Engine) vColumnBox(cols [][]Box, height float64) Box {
+	if len(cols) == 1 {
+		return VBoxTo(height, cols[0]...)
+	}
+
+	textWidth := e.pageTextWidth()
+	width := e.columnWidth(textWidth, len(cols))
+	var row []Box
+	for j, col := range cols {
+		if j > 0 {
+			if e.ColumnRule > 0 {
+				gap := (e.ColumnSep - e.ColumnRule) / 2
+				rule := &artifactBox{Box: Rule(e.ColumnRule, height, 0), e: e}
+				row = append(row, Kern(gap), rule, Kern(gap))
+			} else {
+				row = append(row, Kern(e.ColumnSep))
+			}
+		}
+		box := VBoxTo(height, col...).(*vBox)
+		box.Width = width
+		row = append(row, box)
+	}
+	return HBoxTo(textWidth, row...)
+}
+
+// vFirstBaseline returns the distance from the start of vList to the first
+// baseline.
+func vFirstBaseline(vList []Box) float64 {
+	y := 0.0
+	for _, box := range vList {
+		ext := box.Extent()
+		if !ext.WhiteSpaceOnly {
+			return y + ext.Height
+		}
+		y += ext.Height + ext.Depth
+	}
+	return y
+}
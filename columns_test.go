@@ -0,0 +1,98 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColumnWidth(t *testing.T) {
+	e := &Engine{
+		TextWidth: 300,
+		ColumnSep: 20,
+	}
+	if w := e.ColumnWidth(); w != 300 {
+		t.Errorf("expected width 300, got %g", w)
+	}
+	e.SetColumns(3)
+	if w := e.ColumnWidth(); w != 260.0/3 {
+		t.Errorf("expected width %g, got %g", 260.0/3, w)
+	}
+}
+
+func TestColumnBalance(t *testing.T) {
+	e := &Engine{
+		TextWidth:    200,
+		TextHeight:   100,
+		ColumnSep:    10,
+		BaseLineSkip: 10,
+	}
+	e.SetColumns(2)
+	for range 7 {
+		e.VAddBox(Rule(95, 8, 2))
+		e.VAddPenalty(0)
+	}
+
+	page := e.makePage(true)
+	if len(e.vList) != 0 {
+		t.Fatalf("%d items left over", len(e.vList))
+	}
+
+	parts := page.(*vBox).Contents
+	if len(parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(parts))
+	}
+	ext := parts[0].Extent()
+	// four lines in the first column: 8+10+10+10
+	if math.Abs(ext.Height-38) > balanceTolerance {
+		t.Errorf("expected balanced height 38, got %g", ext.Height)
+	}
+}
+
+func TestColumnSwitch(t *testing.T) {
+	e := &Engine{
+		TextWidth:    200,
+		TextHeight:   100,
+		ColumnSep:    10,
+		BaseLineSkip: 10,
+	}
+	e.VAddBox(Rule(200, 8, 2))
+	e.SetColumns(2)
+	for range 20 {
+		e.VAddBox(Rule(95, 8, 2))
+		e.VAddPenalty(0)
+	}
+
+	page := e.makePage(false)
+	parts := page.(*vBox).Contents
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(parts))
+	}
+	if w := parts[0].Extent().Width; w != 200 {
+		t.Errorf("expected one-column part of width 200, got %g", w)
+	}
+	cols := parts[1].(*hBox).Contents
+	if len(cols) != 3 {
+		t.Fatalf("expected two columns and a gap, got %d items", len(cols))
+	}
+
+	// The first part uses 10 units, leaving room for 9 lines per column.
+	if len(e.vList) != 4 {
+		t.Errorf("expected 2 lines and penalties left over, got %d items", len(e.vList))
+	}
+}
@@ -372,7 +372,8 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 	}
 	hList = append(hList, &hModePenalty{Penalty: PenaltyForceBreak})
 
-	lineWidth := &Glue{Length: e.TextWidth}
+	textWidth := e.ColumnWidth()
+	lineWidth := &Glue{Length: textWidth}
 	lineWidth = lineWidth.Minus(e.LeftSkip).Minus(e.RightSkip)
 
 	br := &knuthPlassLineBreaker{
@@ -394,30 +395,37 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 	var xxx [][]float64
 
 	prevPos := 0
-	for _, pos := range breaks {
+	var openRecords []*hRecord
+	for i, pos := range breaks {
 		startPos = append(startPos, prevPos)
-		var currentLine []Box
+
+		// The positions of the items in the horizontal mode list, used to
+		// mark the potential breakpoints.
+		var items []Box
 		if e.LeftSkip != nil {
-			currentLine = append(currentLine, e.LeftSkip)
+			items = append(items, e.LeftSkip)
 		}
 		for _, item := range hList[prevPos:pos] {
 			switch h := item.(type) {
 			case *Glue:
-				currentLine = append(currentLine, h)
+				items = append(items, h)
 			case *hModeBox:
-				currentLine = append(currentLine, h.Box)
+				items = append(items, h.Box)
 			}
 		}
+		if p, ok := hList[pos].(*hModePenalty); ok && p.pre != nil {
+			items = append(items, p.pre)
+		}
 		hLists = append(hLists, hList[prevPos:pos])
 		if e.RightSkip != nil {
-			currentLine = append(currentLine, e.RightSkip)
+			items = append(items, e.RightSkip)
 		}
-		xx := horizontalLayout(leftMargin, e.TextWidth, currentLine...)
+		xx := horizontalLayout(leftMargin, textWidth, items...)
 		if e.LeftSkip != nil {
 			xx = xx[1:]
 		}
 		if e.RightSkip == nil {
-			xx = append(xx, e.TextWidth)
+			xx = append(xx, textWidth)
 		}
 		xxx = append(xxx, xx)
 
@@ -433,8 +441,10 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 			}
 		}
 
+		var currentLine []Box
+		currentLine, openRecords = e.lineMaterial(hList, startPos[i], pos, i+1, e.tabStops, openRecords)
 		lineContents = append(lineContents, currentLine)
-		lineBox := HBoxTo(e.TextWidth, currentLine...)
+		lineBox := HBoxTo(textWidth, currentLine...)
 		lineBoxes = append(lineBoxes, lineBox)
 	}
 
@@ -462,11 +472,17 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 	b.SetLineWidth(0.5)
 	b.MoveTo(leftMargin, 0)
 	b.LineTo(leftMargin, bottomMargin+visualHeight+topMargin)
-	b.MoveTo(leftMargin+e.TextWidth, 0)
-	b.LineTo(leftMargin+e.TextWidth, bottomMargin+visualHeight+topMargin)
+	b.MoveTo(leftMargin+textWidth, 0)
+	b.LineTo(leftMargin+textWidth, bottomMargin+visualHeight+topMargin)
 	b.Stroke()
 	b.PopGraphicsState()
 
+	// Drawing the lines reports the locations of recorded spans.  These
+	// refer to the debug page and are discarded.
+	root := e.root()
+	nRecords := len(root.records)
+	defer func() { root.records = root.records[:nRecords] }()
+
 	x := float64(leftMargin)
 	y := bottomMargin + visualHeight
 	for i, box := range lineBoxes {
@@ -494,7 +510,7 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 				extra = append(extra, h.Box)
 				x += h.width
 			}
-			if x >= leftMargin+e.TextWidth+72 {
+			if x >= leftMargin+textWidth+72 {
 				break
 			}
 			pos++
@@ -502,13 +518,13 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 		b.PushGraphicsState()
 		overflow := HBox(extra...)
 		ext = overflow.Extent()
-		b.Rectangle(xEnd, y-ext.Depth, leftMargin+e.TextWidth+72-xEnd, ext.Height+ext.Depth)
+		b.Rectangle(xEnd, y-ext.Depth, leftMargin+textWidth+72-xEnd, ext.Height+ext.Depth)
 		b.ClipNonZero()
 		b.EndPath()
 		overflow.Draw(b, xEnd, y)
 		b.SetExtGState(gs)
 		b.SetFillColor(color.DeviceRGB{1, 1, 1})
-		b.Rectangle(xEnd, y-ext.Depth, leftMargin+e.TextWidth+72-xEnd, ext.Height+ext.Depth)
+		b.Rectangle(xEnd, y-ext.Depth, leftMargin+textWidth+72-xEnd, ext.Height+ext.Depth)
 		b.Fill()
 		b.PopGraphicsState()
 
@@ -530,38 +546,38 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 		b.PushGraphicsState()
 		b.SetLineWidth(3.5)
 		b.SetStrokeColor(color.DeviceGray(0.9))
-		b.MoveTo(leftMargin+e.TextWidth+72+1.5, 0)
-		b.LineTo(leftMargin+e.TextWidth+72+1.5, bottomMargin+visualHeight+topMargin)
+		b.MoveTo(leftMargin+textWidth+72+1.5, 0)
+		b.LineTo(leftMargin+textWidth+72+1.5, bottomMargin+visualHeight+topMargin)
 		b.Stroke()
 		b.SetLineWidth(1.5)
 		b.SetStrokeColor(color.DeviceGray(0.8))
-		b.MoveTo(leftMargin+e.TextWidth+72+0.5, 0)
-		b.LineTo(leftMargin+e.TextWidth+72+0.5, bottomMargin+visualHeight+topMargin)
+		b.MoveTo(leftMargin+textWidth+72+0.5, 0)
+		b.LineTo(leftMargin+textWidth+72+0.5, bottomMargin+visualHeight+topMargin)
 		b.Stroke()
 		b.SetLineWidth(0.5)
 		b.SetStrokeColor(color.DeviceGray(0.6))
-		b.MoveTo(leftMargin+e.TextWidth+72, 0)
-		b.LineTo(leftMargin+e.TextWidth+72, bottomMargin+visualHeight+topMargin)
+		b.MoveTo(leftMargin+textWidth+72, 0)
+		b.LineTo(leftMargin+textWidth+72, bottomMargin+visualHeight+topMargin)
 		b.Stroke()
 		b.PopGraphicsState()
 
 		b.TextBegin()
 		b.TextSetFont(F, 6)
 		b.SetFillColor(annotationColor)
-		b.TextFirstLine(leftMargin+e.TextWidth+72+10, y+4)
+		b.TextFirstLine(leftMargin+textWidth+72+10, y+4)
 		total := totalWidthAndGlue(lineContents[i])
-		b.TextShow(fmt.Sprintf("%+.1f", e.TextWidth-total.Length))
+		b.TextShow(fmt.Sprintf("%+.1f", textWidth-total.Length))
 		var r float64
-		if total.Length > e.TextWidth+0.05 {
-			r = (e.TextWidth - total.Length) / total.Shrink.Val
+		if total.Length > textWidth+0.05 {
+			r = (textWidth - total.Length) / total.Shrink.Val
 			label := fmt.Sprintf(" / %.1f (%.0f%%)", total.Shrink.Val, -100*r)
 			if total.Stretch.Order > 0 {
 				r = 0
 				label = " / inf"
 			}
 			b.TextShow(label)
-		} else if total.Length < e.TextWidth-0.05 {
-			r = (e.TextWidth - total.Length) / total.Stretch.Val
+		} else if total.Length < textWidth-0.05 {
+			r = (textWidth - total.Length) / total.Stretch.Val
 			label := fmt.Sprintf(" / %.1f (%.0f%%)", total.Stretch.Val, 100*r)
 			if total.Stretch.Order > 0 {
 				r = 0
@@ -591,7 +607,7 @@ func (e *Engine) DebugLineBreaks(tree *pagetree.Writer, rm *pdf.ResourceManager,
 		MediaBox: &pdf.Rectangle{
 			LLx: 0,
 			LLy: 0,
-			URx: leftMargin + e.TextWidth + rightMargin,
+			URx: leftMargin + textWidth + rightMargin,
 			URy: topMargin + visualHeight + bottomMargin,
 		},
 		Resources: b.Resources,
@@ -20,9 +20,11 @@ import (
 	"math"
 	"unicode"
 
+	"seehuhn.de/go/geom/vec"
 	"seehuhn.de/go/sfnt/glyph"
 
 	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/annotation"
 	"seehuhn.de/go/pdf/font"
 	"seehuhn.de/go/pdf/graphics/content/builder"
 	"seehuhn.de/go/pdf/page"
@@ -43,6 +45,7 @@ type hModePenalty struct {
 	Penalty float64
 	width   float64
 	flagged bool
+	pre     Box // material added at the end of the line, if broken here
 }
 
 // Engine is the main layout engine.
@@ -50,6 +53,8 @@ type Engine struct {
 	PageSize *pdf.Rectangle
 
 	TextWidth   float64
+	ColumnSep   float64 // horizontal space between columns
+	ColumnRule  float64 // width of the rule between columns, or 0 for no rule
 	ParIndent   *Glue
 	LeftSkip    *Glue
 	RightSkip   *Glue
@@ -59,38 +64,124 @@ type Engine struct {
 	TopSkip      float64 // TODO(voss): rename this, because it's not a "skip"?
 	BottomGlue   *Glue
 	BaseLineSkip float64 // TODO(voss): rename this, because it's not a "skip"?
+	BaselineGrid bool    // place all baselines on a grid, see VAddBox
 	ParSkip      *Glue
 
+	OptimalPageBreaks bool // choose page breaks globally, see AppendPages for limits
+
+	CrossRefs *CrossRefs // label locations from earlier layout passes
+	Tagged    bool       // produce a tagged PDF, see BeginStruct
+
 	InterLinePenalty float64
 	ClubPenalty      float64
 	WidowPenalty     float64
 
+	// HyphenPenalty is the penalty for breaking a line at a soft hyphen.
+	// The default of zero makes such breaks as good as breaks at spaces;
+	// TeX uses 50.
+	HyphenPenalty float64
+
 	PageNumber     int
 	BeforePageFunc func(int, *builder.Builder) error
 	AfterPageFunc  func(int, *builder.Builder) error
 	AfterCloseFunc func(p *page.Page) error
 
+	// PageSnapshotFunc, if set, is called with a snapshot of the contents
+	// of each page, before the page is drawn.  See [Snapshot].
+	PageSnapshotFunc func(int, *Snapshot)
+
 	DebugPageNumber int
 
-	hList      []any // list of *hModeBox, *Glue, *hModePenalty
+	hList      []any      // list of *hModeBox, *Glue, *hModePenalty
+	hRecords   []*hRecord // spans started by HBeginRecord, not yet ended
+	tabStops   []TabStop  // tab stops for the current paragraph
 	afterPunct bool
 	afterSpace bool
+	spaceNext  bool // attach a space to the text of the next word
+
+	vList        []Box
+	prevDepth    float64
+	columns      int           // number of columns for new material
+	pageColumns  int           // number of columns at the start of vList
+	template     *PageTemplate // page template for new material
+	pageTemplate *PageTemplate // page template for the next page
+	vPlan        []int         // pre-computed page breaks, relative to page starts
+	vRecordCB    []func(*BoxInfo)
+	vHeading     string // structure type for the next paragraph or box, see VMarkHeading
+	records      []*boxRecord
+	headings     []*Heading
+	labels       map[string]int
+	indexMarks   []*indexMark
+	dests        map[string]*BoxInfo
+	linkDests    map[string]bool         // destinations of the links placed so far
+	pageAnnots   []annotation.Annotation // annotations for the current page
+
+	structRoot    *StructElem
+	structCur     *StructElem
+	pageMCIDs     []*StructElem // structure elements for the MCIDs on the current page
+	pageMCRefs    []*mcRef      // marked-content references on the current page
+	structParents []pdf.Array   // parent tree entries, indexed by StructParents
+	repeating     int           // >0 while drawing repeated material, see repeatedBox
+	inArtifact    int           // >0 while drawing an artifact
+	marked        *StructElem   // element of the open marked-content sequence
+
+	vKeepDepth   int  // nesting depth of VBeginKeep groups
+	vKeepStarted bool // whether the outermost group contains a box
+	vKeepNext    int  // pending VKeepWithNext request
+	vKeepBoxes   int  // number of boxes still to keep with the previous one
+
+	parent  *Engine     // the engine containing a table cell, or nil
+	measure *cellWidths // if set, measure the contents instead of breaking lines
+}
 
-	vList     []Box
-	prevDepth float64
-	vRecordCB []func(*BoxInfo)
-	records   []*boxRecord
+// root returns the engine which places material on the pages.  This differs
+// from e for the engines used to typeset table cells.
+func (e *Engine) root() *Engine {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e
 }
 
 // BoxInfo describes the location of a box after page breaking.
+// All coordinates are given in default user space.
 type BoxInfo struct {
 	PageRef pdf.Reference
-	BBox    *pdf.Rectangle
+	BBox    *pdf.Rectangle // smallest rectangle containing the box
 	PageNo  int
+	Line    int // line within the paragraph, for horizontal material
+
+	// Quad gives the corners of the box, in counter-clockwise order starting
+	// at the lower left corner of the box.  This differs from BBox if the box
+	// has been rotated or skewed.
+	Quad [4]vec.Vec2
+}
+
+// isRect reports whether the box is an axis-aligned rectangle, so that BBox
+// describes the box exactly.  Small rounding errors are ignored.
+func (bi *BoxInfo) isRect() bool {
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) < eps
+	}
+	for _, p := range bi.Quad {
+		if !near(p.X, bi.BBox.LLx) && !near(p.X, bi.BBox.URx) ||
+			!near(p.Y, bi.BBox.LLy) && !near(p.Y, bi.BBox.URy) {
+			return false
+		}
+	}
+	return true
 }
 
 // HAddText adds text to the horizontal mode list.
 // Spaces in the text are converted to glue, and words are converted to boxes.
+//
+// If tab stops have been set using [Engine.SetTabStops], tab characters are
+// converted to tabs.
+//
+// A soft hyphen (U+00AD) marks a place where a word may be broken.  If a line
+// ends there, a hyphen is added at the end of the line.  In the PDF file,
+// this hyphen is mapped to U+00AD, so that text extraction can restore the
+// original word.
 func (e *Engine) HAddText(F *FontInfo, text string) {
 	if len(e.hList) == 0 && e.ParIndent != nil {
 		e.hList = append(e.hList, e.ParIndent)
@@ -119,27 +210,36 @@ func (e *Engine) HAddText(F *FontInfo, text string) {
 
 	var run []rune
 	flushSpace := func() {
-		if spaceGID != 0 {
-			gg := []font.Glyph{
-				{
-					GID:     spaceGID,
-					Text:    string(run),
-					Advance: 0, // no width for space glyph, since we add glue below
-				},
+		var prevText *TextBox
+		if k := len(e.hList); k > 0 {
+			if box, ok := e.hList[k-1].(*hModeBox); ok {
+				prevText, _ = box.Box.(*TextBox)
 			}
+		}
 
-			var prevText *TextBox
-			if k := len(e.hList); k > 0 {
-				if box, ok := e.hList[k-1].(*hModeBox); ok {
-					prevText, _ = box.Box.(*TextBox)
-				}
+		// Every run of white space is represented by exactly one space
+		// character in the extracted text.
+		if spaceGID != 0 {
+			g := font.Glyph{
+				GID:     spaceGID,
+				Text:    " ",
+				Advance: 0, // no width for space glyph, since we add glue below
 			}
-			if prevText != nil {
-				prevText.Glyphs.Seq = append(prevText.Glyphs.Seq, gg...)
+			if prevText != nil && prevText.F == F {
+				prevText.Glyphs.Seq = append(prevText.Glyphs.Seq, g)
 			} else {
-				box := &TextBox{F: F, Glyphs: &font.GlyphSeq{Seq: gg}}
+				box := &TextBox{F: F, Glyphs: &font.GlyphSeq{Seq: []font.Glyph{g}}}
 				e.hList = append(e.hList, &hModeBox{Box: box})
 			}
+		} else if prevText != nil && len(prevText.Glyphs.Seq) > 0 {
+			// The font has no space glyph, so we attach the space to the
+			// text of the last glyph of the previous word.
+			last := &prevText.Glyphs.Seq[len(prevText.Glyphs.Seq)-1]
+			last.Text += " "
+		} else {
+			// Without a previous word, the space is attached to the text
+			// of the next word instead.
+			e.spaceNext = true
 		}
 
 		// if len(run) == 1 && run[0] == 0x200B { // ZERO WIDTH SPACE
@@ -157,6 +257,10 @@ func (e *Engine) HAddText(F *FontInfo, text string) {
 
 	flushRunes := func() {
 		gg := F.Font.Layout(nil, F.Size, string(run))
+		if e.spaceNext && len(gg.Seq) > 0 {
+			gg.Seq[0].Text = " " + gg.Seq[0].Text
+			e.spaceNext = false
+		}
 		box := &TextBox{F: F, Glyphs: gg}
 		e.hList = append(e.hList, &hModeBox{
 			Box:   box,
@@ -165,7 +269,50 @@ func (e *Engine) HAddText(F *FontInfo, text string) {
 		run = run[:0]
 	}
 
+	addHyphen := func() {
+		hyphen := F.Font.Layout(nil, F.Size, "-")
+		for i := range hyphen.Seq {
+			hyphen.Seq[i].Text = ""
+		}
+		if len(hyphen.Seq) > 0 {
+			hyphen.Seq[0].Text = "\u00AD"
+		}
+		e.hList = append(e.hList, &hModePenalty{
+			Penalty: e.HyphenPenalty,
+			width:   hyphen.TotalWidth(),
+			flagged: true,
+			pre:     &TextBox{F: F, Glyphs: hyphen},
+		})
+	}
+
 	for _, r := range text {
+		if r == '\t' && len(e.tabStops) > 0 {
+			if len(run) > 0 {
+				if e.afterSpace {
+					flushSpace()
+				} else {
+					flushRunes()
+				}
+			}
+			e.HAddTab()
+			e.afterSpace = false
+			e.afterPunct = false
+			continue
+		}
+		if r == 0x00AD { // SOFT HYPHEN
+			if len(run) > 0 {
+				if e.afterSpace {
+					flushSpace()
+				} else {
+					flushRunes()
+				}
+			}
+			addHyphen()
+			e.afterSpace = false
+			e.afterPunct = false
+			continue
+		}
+
 		if unicode.IsSpace(r) &&
 			r != 0x00A0 && // NO-BREAK SPACE
 			r != 0x2007 && // FIGURE SPACE
@@ -196,28 +343,83 @@ func (e *Engine) HAddText(F *FontInfo, text string) {
 	}
 }
 
+// HAddBox adds a box to the horizontal mode list.  The box is placed on the
+// current line, like a word.
+func (e *Engine) HAddBox(box Box) {
+	if len(e.hList) == 0 && e.ParIndent != nil {
+		e.hList = append(e.hList, e.ParIndent)
+	}
+	e.hList = append(e.hList, &hModeBox{
+		Box:   box,
+		width: box.Extent().Width,
+	})
+	e.afterSpace = false
+	e.afterPunct = false
+}
+
 // HAddGlue adds a glue item to the horizontal mode list.
 func (e *Engine) HAddGlue(g *Glue) {
 	e.hList = append(e.hList, g)
 }
 
 // VAddGlue adds a glue item to the vertical mode list.
+// If BaselineGrid is set, the glue is used at its natural length.
 func (e *Engine) VAddGlue(g *Glue) {
 	// TODO(voss): check for infinite shrinkability
+	if e.vKeeping() {
+		e.vList = append(e.vList, penalty(PenaltyPreventBreak))
+	}
+	if e.BaselineGrid {
+		g = g.Clone()
+		g.Stretch, g.Shrink = glueAmount{}, glueAmount{}
+	}
 	e.vList = append(e.vList, g)
 }
 
 // VAddBox adds a box to the vertical mode list.
 // Appropriate interline glue is inserted automatically.
+//
+// If BaselineGrid is set, space is inserted so that the distance between
+// consecutive baselines is a multiple of BaseLineSkip.  Together with
+// TopSkip, this places all baselines on a common grid, measured from the top
+// of the text area.  Boxes which are taller than a line are padded to whole
+// grid units.
 func (e *Engine) VAddBox(b Box) {
 	ext := b.Extent()
-	if len(e.vList) > 0 {
+	if e.measure != nil {
+		e.measure.addBox(ext.Width)
+	}
+	if len(e.vList) > 0 && e.BaselineGrid && e.BaseLineSkip > 0 {
+		dist := e.prevDepth + ext.Height + vTrailingSpace(e.vList)
+		lines := max(math.Ceil(dist/e.BaseLineSkip-eps), 1)
+		if pad := lines*e.BaseLineSkip - dist; pad > eps {
+			e.vList = append(e.vList, Kern(pad))
+		}
+	} else if len(e.vList) > 0 {
 		gap := ext.Height + e.prevDepth
 		if gap+eps < e.BaseLineSkip {
 			e.vList = append(e.vList, Kern(e.BaseLineSkip-gap))
 		}
 	}
-	if len(e.vRecordCB) > 0 {
+	if e.Tagged {
+		heading := e.vHeading
+		if heading != "" {
+			e.vHeading = ""
+			e.BeginStruct(heading)
+		}
+		b = e.tagBox(b)
+		if heading != "" {
+			e.EndStruct()
+		}
+	}
+	sb, _ := b.(*splitBox)
+	if sb != nil && len(e.vRecordCB) > 0 {
+		// Keep the box splittable; the callbacks are called for its first
+		// part.
+		sb.e, sb.cb = e, e.vRecordCB
+		e.vRecordCB = nil
+		e.vList = append(e.vList, sb)
+	} else if len(e.vRecordCB) > 0 {
 		e.vList = append(e.vList, &recordPageLocation{
 			Box: b,
 			e:   e,
@@ -228,14 +430,87 @@ func (e *Engine) VAddBox(b Box) {
 		e.vList = append(e.vList, b)
 	}
 	e.prevDepth = ext.Depth
+
+	if e.vKeepBoxes > 0 {
+		e.vKeepBoxes--
+	}
+	if e.vKeepNext > 0 {
+		e.vKeepBoxes = max(e.vKeepBoxes, e.vKeepNext)
+		e.vKeepNext = 0
+	}
+	e.vKeepStarted = e.vKeepDepth > 0
+	if sb != nil {
+		// A split inside the box is a break point after the start of the
+		// box, like a penalty added next.
+		sb.keep = e.vKeeping()
+	}
+}
+
+// vTrailingSpace returns the total length of the white space at the end of
+// vList.
+func vTrailingSpace(vList []Box) float64 {
+	var length float64
+	for i := len(vList) - 1; i >= 0; i-- {
+		ext := vList[i].Extent()
+		if !ext.WhiteSpaceOnly {
+			break
+		}
+		length += ext.Height + ext.Depth
+	}
+	return length
+}
+
+// gridCeil rounds the vertical position y, measured from the top of the text
+// area, up to the next line of the baseline grid.
+func (e *Engine) gridCeil(y float64) float64 {
+	if e.BaseLineSkip <= 0 {
+		return y
+	}
+	k := math.Ceil((y-e.TopSkip)/e.BaseLineSkip - eps)
+	return e.TopSkip + k*e.BaseLineSkip
 }
 
 // VAddPenalty adds a penalty to the vertical mode list.
 // Penalties influence where page breaks occur.
 func (e *Engine) VAddPenalty(p float64) {
+	if e.vKeeping() && p != PenaltyForceBreak {
+		p = PenaltyPreventBreak
+	}
 	e.vList = append(e.vList, penalty(p))
 }
 
+// VBeginKeep starts a group of vertical material which must not be split
+// across pages.  The group ends with the matching call to [Engine.VEndKeep].
+// Groups can be nested.  Forced page breaks inside a group are still
+// honoured.
+func (e *Engine) VBeginKeep() {
+	if e.vKeepDepth == 0 {
+		e.vKeepStarted = false
+	}
+	e.vKeepDepth++
+}
+
+// VEndKeep ends a group started by [Engine.VBeginKeep].
+func (e *Engine) VEndKeep() {
+	if e.vKeepDepth > 0 {
+		e.vKeepDepth--
+	}
+}
+
+// VKeepWithNext requests that the next box added to the vertical mode list
+// is placed on the same page as the n boxes which follow it.
+// This can be used to prevent headings from being separated from the
+// first lines of the following text.
+func (e *Engine) VKeepWithNext(n int) {
+	e.vKeepNext = max(e.vKeepNext, n)
+}
+
+// vKeeping returns true if page breaks are currently suppressed
+// by [Engine.VBeginKeep] or [Engine.VKeepWithNext].
+func (e *Engine) vKeeping() bool {
+	return e.vKeepBoxes > 0 || e.vKeepDepth > 0 && e.vKeepStarted
+}
+
 var (
 	PenaltyPreventBreak = math.Inf(+1)
 	PenaltyForceBreak   = math.Inf(-1)
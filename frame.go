@@ -0,0 +1,284 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// Sides holds one value for each side of a box.
+type Sides struct {
+	Top, Right, Bottom, Left float64
+}
+
+// AllSides returns a Sides value which uses v for all four sides.
+func AllSides(v float64) Sides {
+	return Sides{Top: v, Right: v, Bottom: v, Left: v}
+}
+
+// Frame describes a border and background drawn around a box.
+// Use [Frame.Box] to decorate a box, or pass [Frame.Split] to
+// [Engine.VAddSplittable] for material which may be broken across pages.
+type Frame struct {
+	Padding Sides // space between the border and the contents
+	Border  Sides // width of the border on each side
+
+	// BorderColor is the colour of the border, default black.  The
+	// colours of individual sides can be changed using TopColor,
+	// RightColor, BottomColor and LeftColor.
+	BorderColor color.Color
+
+	TopColor, RightColor, BottomColor, LeftColor color.Color
+
+	// Radius, if positive, is the radius of the rounded outer corners.
+	// The radius is reduced to at most half the width or height of the
+	// frame.
+	Radius float64
+
+	Background color.Color // fill colour for the inside of the frame, or nil
+}
+
+// Box returns a box which shows the given box inside the frame.  The
+// baseline of the result is the baseline of the contents, so that framed
+// boxes can be used in both horizontal and vertical mode.
+func (f *Frame) Box(contents Box) Box {
+	return &frameBox{frame: f, contents: contents, top: true, bottom: true}
+}
+
+// Split decorates one part of a splittable box.  Where the box is split
+// across pages, the frame is left open: the border, padding and rounded
+// corners are omitted at the break.  Split can be used as a [SplitFrame].
+func (f *Frame) Split(part Box, first, last bool) Box {
+	return &frameBox{frame: f, contents: part, top: first, bottom: last}
+}
+
+type frameBox struct {
+	frame    *Frame
+	contents Box
+
+	// top and bottom indicate whether the top and bottom edges of the
+	// frame are drawn
+	top, bottom bool
+
+	e *Engine // for tagged PDF, see Engine.markArtifacts
+}
+
+// sides returns the border widths and padding used for the box, taking
+// open edges into account.
+func (obj *frameBox) sides() (border, padding Sides) {
+	border, padding = obj.frame.Border, obj.frame.Padding
+	if !obj.top {
+		border.Top, padding.Top = 0, 0
+	}
+	if !obj.bottom {
+		border.Bottom, padding.Bottom = 0, 0
+	}
+	return border, padding
+}
+
+// Extent implements the [Box] interface.
+func (obj *frameBox) Extent() *BoxExtent {
+	ext := obj.contents.Extent()
+	border, padding := obj.sides()
+	return &BoxExtent{
+		Width:  ext.Width + border.Left + padding.Left + padding.Right + border.Right,
+		Height: ext.Height + border.Top + padding.Top,
+		Depth:  ext.Depth + border.Bottom + padding.Bottom,
+	}
+}
+
+// Draw implements the [Box] interface.
+func (obj *frameBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	f := obj.frame
+	ext := obj.Extent()
+	border, padding := obj.sides()
+
+	x0 := xPos
+	x1 := xPos + ext.Width
+	y0 := yPos - ext.Depth
+	y1 := yPos + ext.Height
+
+	// corner radii, in the order bottom-left, bottom-right, top-right, top-left
+	var radii [4]float64
+	if f.Radius > 0 {
+		if obj.bottom {
+			radii[0], radii[1] = f.Radius, f.Radius
+		}
+		if obj.top {
+			radii[2], radii[3] = f.Radius, f.Radius
+		}
+		radii = clampRadii(radii, x1-x0, y1-y0)
+	}
+
+	if f.Background != nil {
+		decorate(obj.e, page, func() {
+			page.PushGraphicsState()
+			page.SetFillColor(f.Background)
+			roundedRect(page, x0, y0, x1, y1, radii)
+			page.Fill()
+			page.PopGraphicsState()
+		})
+	}
+
+	obj.contents.Draw(page, xPos+border.Left+padding.Left, yPos)
+
+	if border != (Sides{}) {
+		decorate(obj.e, page, func() {
+			f.drawBorder(page, x0, y0, x1, y1, border, radii)
+		})
+	}
+}
+
+// drawBorder draws the border of a frame with the given outer edges, border
+// widths and corner radii.
+func (f *Frame) drawBorder(page *builder.Builder, x0, y0, x1, y1 float64, border Sides, radii [4]float64) {
+	// The border is the area between the outer outline and the inner
+	// outline of the frame.
+	ix0 := x0 + border.Left
+	ix1 := x1 - border.Right
+	iy0 := y0 + border.Bottom
+	iy1 := y1 - border.Top
+	innerRadii := [4]float64{
+		max(0, radii[0]-max(border.Bottom, border.Left)),
+		max(0, radii[1]-max(border.Bottom, border.Right)),
+		max(0, radii[2]-max(border.Top, border.Right)),
+		max(0, radii[3]-max(border.Top, border.Left)),
+	}
+	ring := func() {
+		roundedRect(page, x0, y0, x1, y1, radii)
+		roundedRect(page, ix0, iy0, ix1, iy1, innerRadii)
+		page.FillEvenOdd()
+	}
+
+	if f.TopColor == nil && f.RightColor == nil && f.BottomColor == nil && f.LeftColor == nil {
+		page.PushGraphicsState()
+		page.SetFillColor(borderColor(f.BorderColor))
+		ring()
+		page.PopGraphicsState()
+		return
+	}
+
+	// If the sides use different colours, each side is drawn separately,
+	// clipped to the region between the diagonals through its corners.
+	type point struct{ x, y float64 }
+	outer := [4]point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	inner := [4]point{{ix0, iy0}, {ix1, iy0}, {ix1, iy1}, {ix0, iy1}}
+	var diag [4]point
+	for i := range 4 {
+		dx := inner[i].x - outer[i].x
+		dy := inner[i].y - outer[i].y
+		s := 1.0
+		if w := max(math.Abs(dx), math.Abs(dy)); w > 0 {
+			// extend the diagonal across the rounded corner
+			s = max(1, radii[i]/w)
+		}
+		diag[i] = point{outer[i].x + s*dx, outer[i].y + s*dy}
+	}
+	sides := []struct {
+		width float64
+		col   color.Color
+		a, b  int // corners at the ends of the side
+	}{
+		{border.Bottom, f.BottomColor, 0, 1},
+		{border.Right, f.RightColor, 1, 2},
+		{border.Top, f.TopColor, 2, 3},
+		{border.Left, f.LeftColor, 3, 0},
+	}
+	for _, side := range sides {
+		if side.width <= 0 {
+			continue
+		}
+		col := side.col
+		if col == nil {
+			col = f.BorderColor
+		}
+		page.PushGraphicsState()
+		page.MoveTo(outer[side.a].x, outer[side.a].y)
+		page.LineTo(outer[side.b].x, outer[side.b].y)
+		page.LineTo(diag[side.b].x, diag[side.b].y)
+		page.LineTo(diag[side.a].x, diag[side.a].y)
+		page.ClosePath()
+		page.ClipNonZero()
+		page.EndPath()
+		page.SetFillColor(borderColor(col))
+		ring()
+		page.PopGraphicsState()
+	}
+}
+
+func (obj *frameBox) children() []Box {
+	return []Box{obj.contents}
+}
+
+// borderColor returns the colour to use for a border, substituting black
+// for nil.
+func borderColor(col color.Color) color.Color {
+	if col == nil {
+		return color.Black
+	}
+	return col
+}
+
+// roundedRect appends a rectangle with rounded corners to the current
+// path.  The corner radii are given in the order bottom-left, bottom-right,
+// top-right, top-left.  Radii larger than half the smaller side of the
+// rectangle are reduced to this value.
+func roundedRect(page *builder.Builder, x0, y0, x1, y1 float64, radii [4]float64) {
+	if radii == [4]float64{} {
+		page.Rectangle(x0, y0, x1-x0, y1-y0)
+		return
+	}
+	radii = clampRadii(radii, x1-x0, y1-y0)
+
+	r := radii[0]
+	page.MoveTo(x0+r, y0)
+	if r := radii[1]; r > 0 {
+		page.LineToArc(x1-r, y0+r, r, -math.Pi/2, 0)
+	} else {
+		page.LineTo(x1, y0)
+	}
+	if r := radii[2]; r > 0 {
+		page.LineToArc(x1-r, y1-r, r, 0, math.Pi/2)
+	} else {
+		page.LineTo(x1, y1)
+	}
+	if r := radii[3]; r > 0 {
+		page.LineToArc(x0+r, y1-r, r, math.Pi/2, math.Pi)
+	} else {
+		page.LineTo(x0, y1)
+	}
+	if r > 0 {
+		page.LineToArc(x0+r, y0+r, r, math.Pi, 3*math.Pi/2)
+	} else {
+		page.LineTo(x0, y0)
+	}
+	page.ClosePath()
+}
+
+// clampRadii limits each corner radius to half the smaller side of a
+// rectangle with the given width and height, so that the corners do not
+// overlap.
+func clampRadii(radii [4]float64, width, height float64) [4]float64 {
+	rMax := max(0, min(width, height)/2)
+	for i, r := range radii {
+		radii[i] = min(r, rMax)
+	}
+	return radii
+}
@@ -0,0 +1,144 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestFrameExtent(t *testing.T) {
+	f := &Frame{
+		Padding: Sides{Top: 1, Right: 2, Bottom: 3, Left: 4},
+		Border:  AllSides(0.5),
+	}
+	inner := Rule(10, 8, 2)
+
+	ext := f.Box(inner).Extent()
+	want := BoxExtent{Width: 17, Height: 9.5, Depth: 5.5}
+	if *ext != want {
+		t.Errorf("got %v, want %v", ext, &want)
+	}
+
+	// parts of a split frame are open at the break
+	ext = f.Split(inner, true, false).Extent()
+	want = BoxExtent{Width: 17, Height: 9.5, Depth: 2}
+	if *ext != want {
+		t.Errorf("got %v, want %v", ext, &want)
+	}
+	ext = f.Split(inner, false, true).Extent()
+	want = BoxExtent{Width: 17, Height: 8, Depth: 5.5}
+	if *ext != want {
+		t.Errorf("got %v, want %v", ext, &want)
+	}
+}
+
+func TestFrameDraw(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   100,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+
+	plain := &Frame{
+		Padding:    AllSides(3),
+		Border:     AllSides(1),
+		Radius:     4,
+		Background: color.DeviceGray(0.9),
+	}
+	e.VAddBox(plain.Box(Text(fi, "plain frame")))
+
+	sub := &Engine{
+		TextWidth:    280,
+		BaseLineSkip: 12,
+		ParSkip:      Skip(2, 1, 0, 0, 0),
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+	for range 20 {
+		sub.HAddText(fi, "A line of text inside a coloured frame.")
+		sub.EndParagraph()
+	}
+	coloured := &Frame{
+		Padding:   AllSides(3),
+		Border:    Sides{Left: 4, Top: 1, Bottom: 1},
+		LeftColor: color.DeviceRGB{0, 0, 1},
+		Radius:    3,
+	}
+	e.VAddSplittable(sub.MakeVTop(), coloured.Split)
+	var pages []int
+	e.VRecordNextBox(func(bi *BoxInfo) {
+		pages = append(pages, bi.PageNo)
+	})
+	e.VAddBox(Text(fi, "after the frame"))
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0] < 2 {
+		t.Errorf("framed material was not split across pages: %v", pages)
+	}
+}
+
+func TestFrameLargeRadius(t *testing.T) {
+	// The corner radius is much larger than the frame.  The corners must
+	// be reduced so that the outline stays inside the frame.
+	f := &Frame{
+		Border:     AllSides(1),
+		Radius:     100,
+		Background: color.DeviceGray(0.9),
+	}
+	box := f.Box(Kern(20))
+	ext := box.Extent()
+
+	b := builder.New(content.Page, nil, pdf.V1_7)
+	box.Draw(b, 0, 0)
+	if b.Err != nil {
+		t.Fatal(b.Err)
+	}
+	c := pdf.NewCursor(nil)
+	found := false
+	for _, op := range b.Stream {
+		if op.Name != content.OpMoveTo && op.Name != content.OpLineTo && op.Name != content.OpCurveTo {
+			continue
+		}
+		found = true
+		for i := 0; i+1 < len(op.Args); i += 2 {
+			x, _ := c.Number(op.Args[i])
+			y, _ := c.Number(op.Args[i+1])
+			if x < -eps || x > ext.Width+eps || y < -ext.Depth-eps || y > ext.Height+eps {
+				t.Errorf("%s: point (%g, %g) outside the frame", op.Name, x, y)
+			}
+		}
+	}
+	if !found {
+		t.Error("no path found")
+	}
+}
@@ -39,6 +39,10 @@ type Glue struct {
 	Length  float64
 	Stretch glueAmount
 	Shrink  glueAmount
+
+	leader     Box // if set, the space is filled with copies of this box
+	leaderKind LeaderKind
+	e          *Engine // for tagged PDF, see Engine.markArtifacts
 }
 
 func (g *Glue) Plus(other *Glue) *Glue {
@@ -84,9 +88,12 @@ func (g *Glue) Minus(other *Glue) *Glue {
 
 func (g *Glue) Clone() *Glue {
 	return &Glue{
-		Length:  g.Length,
-		Stretch: g.Stretch,
-		Shrink:  g.Shrink,
+		Length:     g.Length,
+		Stretch:    g.Stretch,
+		Shrink:     g.Shrink,
+		leader:     g.leader,
+		leaderKind: g.leaderKind,
+		e:          g.e,
 	}
 }
 
@@ -172,6 +179,48 @@ func totalWidthAndGlue(boxes []Box) *Glue {
 	return res
 }
 
+// glueSet describes how the glue in a box is stretched or shrunk to make
+// the contents fill the box.
+type glueSet struct {
+	ratio float64 // stretch (if positive) or shrink (if negative) per unit
+	order int
+}
+
+// newGlueSet computes the glue setting for contents with the given total
+// size and glue, placed in a box of the given size.
+func newGlueSet(total *Glue, size float64) glueSet {
+	if total.Length < size-eps && total.Stretch.Val > 0 {
+		// contents are too small, stretch all available glue
+		return glueSet{
+			ratio: (size - total.Length) / total.Stretch.Val,
+			order: total.Stretch.Order,
+		}
+	} else if total.Length > size+eps && total.Shrink.Val > 0 {
+		// contents are too large, shrink all available glue
+		q := (total.Length - size) / total.Shrink.Val
+		if total.Shrink.Order == 0 && q > 1 {
+			// glue can't shrink beyond its minimum size
+			q = 1
+		}
+		return glueSet{ratio: -q, order: total.Shrink.Order}
+	}
+	// lay out contents at their natural size
+	return glueSet{}
+}
+
+// adjust returns the amount by which the glue setting changes the size of
+// the given box.
+func (gs glueSet) adjust(box Box) float64 {
+	switch {
+	case gs.ratio > 0:
+		return gs.ratio * getStretch(box, gs.order)
+	case gs.ratio < 0:
+		return gs.ratio * getShrink(box, gs.order)
+	default:
+		return 0
+	}
+}
+
 type glueAmount struct {
 	Val   float64
 	Order int
@@ -4,6 +4,7 @@ go 1.25.0
 
 require (
 	golang.org/x/text v0.40.0
+	seehuhn.de/go/geom v0.7.4
 	seehuhn.de/go/pdf v0.7.4
 	seehuhn.de/go/sfnt v0.7.4
 )
@@ -12,7 +13,6 @@ require (
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	golang.org/x/image v0.44.0 // indirect
 	seehuhn.de/go/dag v0.0.0-20250630092703-dd0e13308cb3 // indirect
-	seehuhn.de/go/geom v0.7.4 // indirect
 	seehuhn.de/go/icc v0.7.4 // indirect
 	seehuhn.de/go/membudget v0.7.4 // indirect
 	seehuhn.de/go/postscript v0.7.4 // indirect
@@ -0,0 +1,212 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+
+	"seehuhn.de/go/geom/matrix"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/graphics/form"
+	"seehuhn.de/go/pdf/page"
+	"seehuhn.de/go/pdf/pagetree"
+)
+
+// Graphics returns a box of the given size, whose contents are drawn by
+// calling draw.  When draw is called, the origin of the coordinate system
+// is at the lower left corner of the box, so that the box covers the
+// rectangle from (0, 0) to (width, height+depth).  The graphics state is
+// saved before draw is called and restored afterwards, and all drawing is
+// clipped to the box.
+func Graphics(width, height, depth float64, draw func(page *builder.Builder)) Box {
+	return &graphicsBox{
+		BoxExtent: BoxExtent{Width: width, Height: height, Depth: depth},
+		draw:      draw,
+	}
+}
+
+type graphicsBox struct {
+	BoxExtent
+	draw func(page *builder.Builder)
+}
+
+// Draw implements the [Box] interface.
+func (obj *graphicsBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	page.PushGraphicsState()
+	page.Transform(matrix.Translate(xPos, yPos-obj.Depth))
+	page.Rectangle(0, 0, obj.Width, obj.Height+obj.Depth)
+	page.ClipNonZero()
+	page.EndPath()
+	obj.draw(page)
+	page.PopGraphicsState()
+}
+
+// ImportedPage is a page of an existing PDF file, which can be placed on
+// pages using [ImportedPage.Box].  The page is embedded as a form XObject,
+// so it is stored in the PDF file only once, no matter how often it is
+// used.
+type ImportedPage struct {
+	// BBox is the visible area of the imported page, taken from the crop
+	// box or media box of the page.  If the page has a /Rotate entry, BBox
+	// describes the rotated page, so that the width and height are swapped
+	// for rotations by 90 and 270 degrees.
+	BBox pdf.Rectangle
+
+	xObj graphics.XObject
+}
+
+// ReadPDFPage imports a page from the given PDF file.  Pages are numbered
+// starting from 0.
+func ReadPDFPage(fname string, pageNo int) (*ImportedPage, error) {
+	r, err := pdf.Open(fname, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ImportPage(r, pageNo)
+}
+
+// ImportPage imports a page from a PDF file.  Pages are numbered starting
+// from 0.  The contents of the page are read immediately, so the file can
+// be closed once the function returns.  The page is shown the way a viewer
+// would display it, taking the /Rotate entry of the page into account.
+func ImportPage(r pdf.Getter, pageNo int) (*ImportedPage, error) {
+	_, pageDict, err := pagetree.GetPage(r, pageNo)
+	if err != nil {
+		return nil, err
+	}
+
+	c := pdf.NewCursor(r)
+	box := pageDict["CropBox"]
+	if box == nil {
+		box = pageDict["MediaBox"]
+	}
+	bbox, err := c.Rectangle(box)
+	if err != nil {
+		return nil, err
+	}
+
+	x := pdf.NewExtractor(r)
+	pg, err := pdf.Decode(pdf.CursorAt(x, nil), pageDict, page.Decode)
+	if err != nil {
+		return nil, err
+	}
+	res := pg.Resources
+	if res == nil {
+		res = &content.Resources{}
+	}
+
+	var ops []content.Operator
+	it := pg.NewIter()
+	for name, args := range it.All() {
+		ops = append(ops, content.Operator{Name: name, Args: slices.Clone(args)})
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	// The /Rotate entry turns the page clockwise.  We apply the rotation
+	// using the form matrix, and describe the rotated page in the bounding
+	// box seen by the caller.
+	M, rotated := rotatePage(bbox, pg.Rotate.Degrees())
+
+	obj := &form.Form{
+		Content: &content.Operators{Ops: ops},
+		Res:     res,
+		BBox:    *bbox,
+		Matrix:  M,
+	}
+	return &ImportedPage{BBox: rotated, xObj: obj}, nil
+}
+
+// rotatePage returns the matrix which turns a page clockwise by the given
+// number of degrees (0, 90, 180 or 270), together with the image of the
+// bounding box under this matrix.
+func rotatePage(bbox *pdf.Rectangle, degrees int) (matrix.Matrix, pdf.Rectangle) {
+	switch degrees {
+	case 90:
+		return matrix.Matrix{0, -1, 1, 0, 0, 0}, pdf.Rectangle{
+			LLx: bbox.LLy, LLy: -bbox.URx, URx: bbox.URy, URy: -bbox.LLx,
+		}
+	case 180:
+		return matrix.Matrix{-1, 0, 0, -1, 0, 0}, pdf.Rectangle{
+			LLx: -bbox.URx, LLy: -bbox.URy, URx: -bbox.LLx, URy: -bbox.LLy,
+		}
+	case 270:
+		return matrix.Matrix{0, 1, -1, 0, 0, 0}, pdf.Rectangle{
+			LLx: -bbox.URy, LLy: bbox.LLx, URx: -bbox.LLy, URy: bbox.URx,
+		}
+	default:
+		return matrix.Identity, *bbox
+	}
+}
+
+// Box returns a box which shows the imported page with the given width and
+// height.  If one of width and height is zero, it is chosen to preserve the
+// aspect ratio of the page.  If both are zero, the page is shown at its
+// original size.
+//
+// The bottom edge of the page is placed on the baseline.
+func (p *ImportedPage) Box(width, height float64) Box {
+	w, h := p.BBox.Dx(), p.BBox.Dy()
+	switch {
+	case width == 0 && height == 0:
+		width, height = w, h
+	case width == 0:
+		width = height * w / h
+	case height == 0:
+		height = width * h / w
+	}
+	return &importedPageBox{
+		BoxExtent: BoxExtent{Width: width, Height: height},
+		page:      p,
+	}
+}
+
+// BoxFit returns a box which shows the imported page at the largest size
+// which fits into the given width and height, preserving the aspect ratio
+// of the page.
+func (p *ImportedPage) BoxFit(maxWidth, maxHeight float64) Box {
+	if maxWidth*p.BBox.Dy() <= maxHeight*p.BBox.Dx() {
+		return p.Box(maxWidth, 0)
+	}
+	return p.Box(0, maxHeight)
+}
+
+type importedPageBox struct {
+	BoxExtent
+	page *ImportedPage
+}
+
+// Draw implements the [Box] interface.
+func (obj *importedPageBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	bbox := &obj.page.BBox
+	sx := obj.Width / bbox.Dx()
+	sy := (obj.Height + obj.Depth) / bbox.Dy()
+	page.PushGraphicsState()
+	page.Transform(matrix.Matrix{
+		sx, 0,
+		0, sy,
+		xPos - sx*bbox.LLx, yPos - obj.Depth - sy*bbox.LLy,
+	})
+	page.DrawXObject(obj.page.xObj)
+	page.PopGraphicsState()
+}
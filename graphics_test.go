@@ -0,0 +1,174 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bytes"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/vec"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/graphics/form"
+	"seehuhn.de/go/pdf/page"
+)
+
+func TestImportPage(t *testing.T) {
+	// Create a small PDF file, which we then import.
+	src := &bytes.Buffer{}
+	pageSize := &pdf.Rectangle{LLx: 10, LLy: 20, URx: 110, URy: 70}
+	page, err := document.WriteSinglePage(src, pageSize, pdf.V1_7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page.SetFillColor(color.DeviceRGB{1, 0, 0})
+	page.Rectangle(20, 30, 50, 20)
+	page.Fill()
+	err = page.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pdf.NewReader(bytes.NewReader(src.Bytes()), int64(src.Len()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportPage(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imported.BBox != *pageSize {
+		t.Errorf("wrong bounding box %v", imported.BBox)
+	}
+	if ops := imported.xObj.(*form.Form).Content.(*content.Operators).Ops; len(ops) == 0 {
+		t.Error("page content was lost")
+	}
+	if ext := imported.Box(0, 0).Extent(); ext.Width != 100 || ext.Height != 50 {
+		t.Errorf("wrong extent %v", ext)
+	}
+	if ext := imported.BoxFit(50, 50).Extent(); ext.Width != 50 || ext.Height != 25 {
+		t.Errorf("wrong extent %v", ext)
+	}
+
+	// Place the imported page, together with a vector graphics box, into a
+	// new document.
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   500,
+		BaseLineSkip: 12,
+	}
+	e.VAddBox(imported.Box(200, 0))
+	called := false
+	e.VAddBox(Graphics(100, 40, 10, func(page *builder.Builder) {
+		called = true
+		page.MoveTo(0, 0)
+		page.LineTo(100, 50)
+		page.Stroke()
+	}))
+	err = e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("drawing callback was not called")
+	}
+}
+
+func TestImportPageRotate(t *testing.T) {
+	pageSize := &pdf.Rectangle{LLx: 10, LLy: 20, URx: 110, URy: 70}
+	cases := []struct {
+		rotate page.Rotation
+		w, h   float64
+	}{
+		{page.Rotate0, 100, 50},
+		{page.Rotate90, 50, 100},
+		{page.Rotate180, 100, 50},
+		{page.Rotate270, 50, 100},
+	}
+	for _, c := range cases {
+		src := &bytes.Buffer{}
+		p, err := document.WriteSinglePage(src, pageSize, pdf.V1_7, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Page.Rotate = c.rotate
+		p.Rectangle(20, 30, 50, 20)
+		p.Fill()
+		err = p.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := pdf.NewReader(bytes.NewReader(src.Bytes()), int64(src.Len()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, err := ImportPage(r, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+
+		deg := c.rotate.Degrees()
+		if ext := imported.Box(0, 0).Extent(); ext.Width != c.w || ext.Height != c.h {
+			t.Errorf("%d: wrong extent %v", deg, ext)
+		}
+
+		// The form matrix must map the page onto the rotated bounding box.
+		f := imported.xObj.(*form.Form)
+		if f.BBox != *pageSize {
+			t.Errorf("%d: wrong form bounding box %v", deg, f.BBox)
+		}
+		M := f.Matrix
+		if M == matrix.Zero {
+			M = matrix.Identity
+		}
+		var got pdf.Rectangle
+		for i, corner := range []vec.Vec2{
+			{X: pageSize.LLx, Y: pageSize.LLy},
+			{X: pageSize.URx, Y: pageSize.LLy},
+			{X: pageSize.LLx, Y: pageSize.URy},
+			{X: pageSize.URx, Y: pageSize.URy},
+		} {
+			v := M.Apply(corner)
+			if i == 0 {
+				got = pdf.Rectangle{LLx: v.X, LLy: v.Y, URx: v.X, URy: v.Y}
+			} else {
+				got.ExtendVec(v)
+			}
+		}
+		if got != imported.BBox {
+			t.Errorf("%d: rotated page %v, bounding box %v", deg, got, imported.BBox)
+		}
+	}
+}
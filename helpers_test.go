@@ -0,0 +1,53 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/font/gofont"
+	"seehuhn.de/go/pdf/font/truetype"
+)
+
+// testFont returns the Go Regular font at 10pt, for use in tests.
+func testFont(t *testing.T) *FontInfo {
+	t.Helper()
+	F, err := gofont.Regular.NewSimple(&truetype.OptionsSimple{
+		Language: language.BritishEnglish,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &FontInfo{Font: F, Size: 10}
+}
+
+// newTestDoc starts a new multi-page PDF document with A4 pages.  The
+// document is written to the returned buffer.
+func newTestDoc(t *testing.T) (*document.MultiPage, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	doc, err := document.WriteMultiPage(buf, document.A4, pdf.V1_7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc, buf
+}
@@ -0,0 +1,179 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// HBeginRecord starts a span of horizontal material whose location is
+// recorded.  The span ends at the matching call to [Engine.HEndRecord], or
+// at the end of the paragraph.  Spans can be nested.
+//
+// Once the span has been placed on a page, cb is called with the location of
+// the span.  If the span is broken across lines, cb is called once for each
+// line, in order.  The Line field of the BoxInfo gives the number of the
+// line within the paragraph.
+func (e *Engine) HBeginRecord(cb func(*BoxInfo)) {
+	rec := &hRecord{e: e, cb: cb}
+	e.hRecords = append(e.hRecords, rec)
+	e.hList = append(e.hList, &hModeBox{Box: &hRecordMark{rec: rec, start: true}})
+}
+
+// HEndRecord ends the span started by the most recent unmatched call to
+// [Engine.HBeginRecord].
+func (e *Engine) HEndRecord() {
+	k := len(e.hRecords) - 1
+	if k < 0 {
+		return
+	}
+	rec := e.hRecords[k]
+	e.hRecords = e.hRecords[:k]
+	e.hList = append(e.hList, &hModeBox{Box: &hRecordMark{rec: rec}})
+}
+
+// hRecord is a span of horizontal material whose location is recorded.
+type hRecord struct {
+	e  *Engine
+	cb func(*BoxInfo)
+}
+
+// hRecordMark marks the start or the end of a recorded span in the
+// horizontal mode list.  When the paragraph is broken into lines, the marks
+// are replaced by an hRecordStart and hRecordEnd pair on every line
+// containing part of the span.
+type hRecordMark struct {
+	rec   *hRecord
+	start bool
+}
+
+func (obj *hRecordMark) Extent() *BoxExtent {
+	return &BoxExtent{}
+}
+
+func (obj *hRecordMark) Draw(page *builder.Builder, xPos, yPos float64) {
+	// pass
+}
+
+// hRecordSegment is the part of a recorded span on a single line.
+type hRecordSegment struct {
+	rec    *hRecord
+	line   int
+	x0     float64
+	height float64
+	depth  float64
+
+	partial bool // the span continues on a previous or following line
+	used    bool // the segment contains visible material
+}
+
+// hRecordStart marks the start of a segment within a line.
+type hRecordStart struct {
+	seg *hRecordSegment
+}
+
+func (obj *hRecordStart) Extent() *BoxExtent {
+	return &BoxExtent{}
+}
+
+func (obj *hRecordStart) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.seg.x0 = xPos
+}
+
+// hRecordEnd marks the end of a segment within a line.  When the end is
+// drawn, the location of the segment is recorded.
+type hRecordEnd struct {
+	seg *hRecordSegment
+}
+
+func (obj *hRecordEnd) Extent() *BoxExtent {
+	return &BoxExtent{}
+}
+
+func (obj *hRecordEnd) Draw(page *builder.Builder, xPos, yPos float64) {
+	seg := obj.seg
+	if seg.partial && !seg.used {
+		// Don't report empty pieces where a span is broken across lines.
+		return
+	}
+	bi := newBoxInfo(page, seg.x0, yPos-seg.depth, xPos, yPos+seg.height)
+	bi.Line = seg.line
+	seg.rec.e.addRecord(bi, []func(*BoxInfo){seg.rec.cb})
+}
+
+// hRecordLine replaces the span marks in the material for one line by
+// segment boundaries.  The argument open lists the spans which are open at
+// the start of the line; the function returns the new line and the spans
+// still open at the end of the line.
+func hRecordLine(line []Box, lineNo int, open []*hRecord) ([]Box, []*hRecord) {
+	if len(open) == 0 && !slices.ContainsFunc(line, isRecordMark) {
+		return line, nil
+	}
+
+	segs := make(map[*hRecord]*hRecordSegment)
+	begin := func(rec *hRecord, partial bool) Box {
+		seg := &hRecordSegment{rec: rec, line: lineNo, partial: partial}
+		segs[rec] = seg
+		return &hRecordStart{seg: seg}
+	}
+
+	res := make([]Box, 0, len(line)+2*len(open))
+	for _, rec := range open {
+		res = append(res, begin(rec, true))
+	}
+	open = slices.Clone(open)
+	for _, box := range line {
+		mark, ok := box.(*hRecordMark)
+		if !ok {
+			res = append(res, box)
+			ext := box.Extent()
+			if ext.WhiteSpaceOnly {
+				continue
+			}
+			for _, seg := range segs {
+				seg.height = max(seg.height, ext.Height)
+				seg.depth = max(seg.depth, ext.Depth)
+				seg.used = true
+			}
+			continue
+		}
+
+		if mark.start {
+			res = append(res, begin(mark.rec, false))
+			open = append(open, mark.rec)
+		} else if seg := segs[mark.rec]; seg != nil {
+			res = append(res, &hRecordEnd{seg: seg})
+			delete(segs, mark.rec)
+			open = slices.DeleteFunc(open, func(rec *hRecord) bool {
+				return rec == mark.rec
+			})
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		seg := segs[open[i]]
+		seg.partial = true
+		res = append(res, &hRecordEnd{seg: seg})
+	}
+	return res, open
+}
+
+func isRecordMark(box Box) bool {
+	_, ok := box.(*hRecordMark)
+	return ok
+}
@@ -0,0 +1,80 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/pdf/document"
+)
+
+func TestHRecord(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    100,
+		TextHeight:   200,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+
+	var word, span []*BoxInfo
+	e.HAddText(fi, "The quick brown ")
+	e.HBeginRecord(func(bi *BoxInfo) { word = append(word, bi) })
+	e.HAddText(fi, "fox")
+	e.HEndRecord()
+	e.HAddText(fi, " jumps ")
+	e.HBeginRecord(func(bi *BoxInfo) { span = append(span, bi) })
+	e.HAddText(fi, "over the lazy dog and keeps on running")
+	e.HEndRecord()
+	e.HAddText(fi, ".")
+	e.EndParagraph()
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(word) != 1 {
+		t.Fatalf("expected 1 piece for the word, got %d", len(word))
+	}
+	fox := Text(fi, "fox").Extent()
+	if w := word[0].BBox.URx - word[0].BBox.LLx; math.Abs(w-fox.Width) > 1e-6 {
+		t.Errorf("expected width %g, got %g", fox.Width, w)
+	}
+	if word[0].PageNo != 1 || word[0].Line != 1 {
+		t.Errorf("wrong location: page %d, line %d", word[0].PageNo, word[0].Line)
+	}
+
+	if len(span) < 2 {
+		t.Fatalf("expected span to be broken across lines, got %d pieces", len(span))
+	}
+	for i, bi := range span {
+		if i > 0 && (bi.Line != span[i-1].Line+1 || bi.BBox.URy >= span[i-1].BBox.URy) {
+			t.Errorf("piece %d: wrong line %d", i, bi.Line)
+		}
+		if bi.BBox.URx <= bi.BBox.LLx {
+			t.Errorf("piece %d: empty rectangle", i)
+		}
+	}
+}
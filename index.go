@@ -0,0 +1,216 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// IndexEntry describes an entry of the back-of-book index.
+type IndexEntry struct {
+	Term    string // the main entry
+	Sub     string // the subentry, or "" for the main entry
+	See     string // refer to another entry, instead of giving page numbers
+	SeeAlso string // refer to a related entry, in addition to page numbers
+}
+
+// indexMark records the page of one use of an index entry.
+type indexMark struct {
+	IndexEntry
+	pageNo int // 0 until the mark has been placed on a page
+}
+
+// VMarkIndex adds an index entry for the next box added in vertical mode.
+func (e *Engine) VMarkIndex(entry IndexEntry) {
+	m := e.newIndexMark(entry)
+	e.VRecordNextBox(func(bi *BoxInfo) {
+		m.pageNo = bi.PageNo
+	})
+}
+
+// HMarkIndex adds an index entry at the current position in the horizontal
+// mode list.
+func (e *Engine) HMarkIndex(entry IndexEntry) {
+	m := e.newIndexMark(entry)
+	anchor := &recordPageLocation{
+		Box: HBox(),
+		e:   e,
+		cb: []func(*BoxInfo){func(bi *BoxInfo) {
+			m.pageNo = bi.PageNo
+		}},
+	}
+	e.hList = append(e.hList, &hModeBox{Box: anchor})
+}
+
+func (e *Engine) newIndexMark(entry IndexEntry) *indexMark {
+	m := &indexMark{IndexEntry: entry}
+	root := e.root()
+	root.indexMarks = append(root.indexMarks, m)
+	if e.CrossRefs != nil {
+		e.CrossRefs.index = append(e.CrossRefs.index, m)
+	}
+	return m
+}
+
+// VAddIndex adds the index to the vertical mode list.  The index is set in
+// two columns, with entries sorted according to the conventions of the given
+// language.  Page numbers are set flush right, separated from the entry by
+// dot leaders, and runs of consecutive pages are merged into ranges.
+//
+// If CrossRefs is set, the page numbers from the previous layout pass are
+// used.  Otherwise, only marks on pages which have already been output are
+// included.
+func (e *Engine) VAddIndex(F *FontInfo, lang language.Tag) {
+	marks := e.indexMarks
+	if e.CrossRefs != nil {
+		marks = e.CrossRefs.prevIndex
+	}
+	entries := buildIndex(marks)
+	if len(entries) == 0 {
+		return
+	}
+
+	columns := max(e.columns, 1)
+	e.SetColumns(2)
+	defer e.SetColumns(columns)
+
+	col := collate.New(lang)
+	for _, term := range sortedTerms(col, entries) {
+		node := entries[term]
+		e.vIndexLine(F, 0, term, node)
+		for _, sub := range sortedTerms(col, node.subs) {
+			e.vIndexLine(F, F.Size, sub, node.subs[sub])
+		}
+	}
+}
+
+// indexNode collects all marks for an entry or subentry of the index.
+type indexNode struct {
+	pages   []int
+	see     []string
+	seeAlso []string
+	subs    map[string]*indexNode
+}
+
+func (node *indexNode) add(m *indexMark) {
+	switch {
+	case m.See != "":
+		if !slices.Contains(node.see, m.See) {
+			node.see = append(node.see, m.See)
+		}
+	case m.pageNo > 0:
+		node.pages = append(node.pages, m.pageNo)
+	}
+	if m.SeeAlso != "" && !slices.Contains(node.seeAlso, m.SeeAlso) {
+		node.seeAlso = append(node.seeAlso, m.SeeAlso)
+	}
+}
+
+// buildIndex groups the index marks by term and subentry.  Marks which have
+// not been placed on a page are ignored, unless they refer to another entry.
+func buildIndex(marks []*indexMark) map[string]*indexNode {
+	entries := make(map[string]*indexNode)
+	get := func(m map[string]*indexNode, key string) *indexNode {
+		node := m[key]
+		if node == nil {
+			node = &indexNode{subs: make(map[string]*indexNode)}
+			m[key] = node
+		}
+		return node
+	}
+	for _, m := range marks {
+		if m.pageNo == 0 && m.See == "" && m.SeeAlso == "" {
+			continue
+		}
+		node := get(entries, m.Term)
+		if m.Sub != "" {
+			node = get(node.subs, m.Sub)
+		}
+		node.add(m)
+	}
+	return entries
+}
+
+func sortedTerms(col *collate.Collator, m map[string]*indexNode) []string {
+	terms := make([]string, 0, len(m))
+	for term := range m {
+		terms = append(terms, term)
+	}
+	slices.SortFunc(terms, col.CompareString)
+	return terms
+}
+
+// pageRanges formats a list of page numbers, merging runs of consecutive
+// pages into ranges.
+func pageRanges(pages []int) string {
+	pages = slices.Clone(pages)
+	slices.Sort(pages)
+	pages = slices.Compact(pages)
+
+	var parts []string
+	for i := 0; i < len(pages); {
+		j := i + 1
+		for j < len(pages) && pages[j] == pages[j-1]+1 {
+			j++
+		}
+		part := strconv.Itoa(pages[i])
+		if j-i > 1 {
+			part += "–" + strconv.Itoa(pages[j-1])
+		}
+		parts = append(parts, part)
+		i = j
+	}
+	return strings.Join(parts, ", ")
+}
+
+// vIndexLine adds the lines for one index entry to the vertical mode list.
+func (e *Engine) vIndexLine(F *FontInfo, indent float64, term string, node *indexNode) {
+	width := e.ColumnWidth()
+	fill := Skip(0, 1, 1, 0, 0)
+	space := F.Size / 4
+
+	text := term
+	if len(node.see) > 0 {
+		text += ", see " + strings.Join(node.see, "; ")
+	}
+	left := Text(F, text)
+
+	if len(node.pages) == 0 {
+		e.VAddBox(HBoxTo(width, Kern(indent), left, fill))
+	} else {
+		right := Text(F, pageRanges(node.pages))
+		dots := DotLeaders(F)
+		x0 := indent + left.Extent().Width + space
+		if x0+dots.Length+space+right.Extent().Width > width {
+			// The page numbers go on a separate line.
+			e.VAddBox(HBoxTo(width, Kern(indent), left, fill))
+			e.VAddBox(HBoxTo(width, Kern(indent+2*F.Size), dots, Kern(space), right))
+		} else {
+			e.VAddBox(HBoxTo(width, Kern(indent), left, Kern(space), dots, Kern(space), right))
+		}
+	}
+
+	if len(node.seeAlso) > 0 {
+		also := Text(F, "see also "+strings.Join(node.seeAlso, "; "))
+		e.VAddBox(HBoxTo(width, Kern(indent+F.Size), also, fill))
+	}
+}
@@ -0,0 +1,122 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+
+	"seehuhn.de/go/pdf/document"
+)
+
+func TestPageRanges(t *testing.T) {
+	cases := []struct {
+		pages []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{3}, "3"},
+		{[]int{5, 3, 4, 3}, "3–5"},
+		{[]int{1, 3, 4, 7}, "1, 3–4, 7"},
+	}
+	for _, c := range cases {
+		if got := pageRanges(c.pages); got != c.want {
+			t.Errorf("%v: expected %q, got %q", c.pages, c.want, got)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   50,
+		BaseLineSkip: 12,
+	}
+
+	e.HAddText(fi, "Zebras ")
+	e.HMarkIndex(IndexEntry{Term: "Zebra"})
+	e.HMarkIndex(IndexEntry{Term: "Äpfel", Sub: "grün"})
+	e.HAddText(fi, "und Äpfel.")
+	e.EndParagraph()
+	e.VMarkIndex(IndexEntry{Term: "Birne", See: "Äpfel"})
+	for range 2 {
+		e.VAddPenalty(PenaltyForceBreak)
+		e.VMarkIndex(IndexEntry{Term: "Äpfel"})
+		e.VAddBox(Rule(10, 10, 0))
+	}
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.VAddIndex(fi, language.German)
+	var lines []string
+	leaders := 0
+	for _, box := range e.vList {
+		if hbox, ok := box.(*hBox); ok {
+			lines = append(lines, boxText(hbox))
+			for _, child := range hbox.Contents {
+				if g, ok := child.(*Glue); ok && g.leader != nil {
+					leaders++
+				}
+			}
+		}
+	}
+	if leaders != 3 {
+		t.Errorf("expected 3 lines with leaders, got %d", leaders)
+	}
+	want := []string{"Äpfel 2–3", "grün 1", "Birne, see Äpfel", "Zebra 1"}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %q", len(want), lines)
+	}
+	for i, line := range lines {
+		line = strings.Join(strings.Fields(strings.ReplaceAll(line, ".", "")), " ")
+		if line != want[i] {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], line)
+		}
+	}
+	if e.ColumnWidth() != 300 {
+		t.Error("column layout not restored")
+	}
+}
+
+// boxText returns the text contained in a box.  The non-empty texts of
+// the children of an hBox are separated by spaces.
+func boxText(box Box) string {
+	switch b := box.(type) {
+	case *TextBox:
+		var text []string
+		for _, g := range b.Glyphs.Seq {
+			text = append(text, g.Text)
+		}
+		return strings.Join(text, "")
+	case *hBox:
+		var text []string
+		for _, child := range b.Contents {
+			if t := boxText(child); t != "" {
+				text = append(text, t)
+			}
+		}
+		return strings.Join(text, " ")
+	}
+	return ""
+}
@@ -67,6 +67,14 @@ func (br *knuthPlassLineBreaker) Run() []int {
 					a := br.active[aIdx]
 
 					r := br.AdjustmentRatio(a, b)
+
+					// If the last active node is about to be removed
+					// without a feasible break, we accept an overfull or
+					// underfull line instead, as TeX does in its final
+					// pass.
+					artificial := len(br.active) == 1 && D == math.Inf(+1) &&
+						(r < -1 || r > br.ρ && pb == PenaltyForceBreak)
+
 					if r < -1 || pb == PenaltyForceBreak {
 						// remove a from the active list
 						copy(br.active[aIdx:], br.active[aIdx+1:])
@@ -77,7 +85,12 @@ func (br *knuthPlassLineBreaker) Run() []int {
 						aIdx++
 					}
 
-					if r >= -1 && r <= br.ρ {
+					if artificial {
+						c := getFitnessClass(r)
+						Ac[c+1] = a
+						Dc[c+1] = a.totalDemerits
+						D = a.totalDemerits
+					} else if r >= -1 && r <= br.ρ {
 						c := getFitnessClass(r)
 						d := br.computeDemerits(r, pb, a, b, c)
 
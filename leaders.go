@@ -0,0 +1,176 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// LeaderKind specifies how the copies of the box are arranged in leaders.
+type LeaderKind int
+
+const (
+	// LeadersAligned places the copies at multiples of the box size,
+	// measured from the edge of the page, so that leaders on different
+	// lines are aligned.
+	LeadersAligned LeaderKind = iota
+
+	// LeadersCentered packs the copies together and centres them within
+	// the space.
+	LeadersCentered
+
+	// LeadersExpanded distributes the leftover space evenly between the
+	// copies.
+	LeadersExpanded
+)
+
+// Leaders returns a copy of the glue g, which fills the space it occupies
+// with copies of box, for example with dots.  The copies are placed at
+// multiples of the box width, measured from the left edge of the page, so
+// that leaders on different lines are aligned.  If box is a rule, see
+// [Rule], a single rule covering the whole space is drawn instead.
+//
+// Leaders can be used both in horizontal and in vertical lists.  In
+// vertical lists, copies of the box are stacked vertically, and rules are
+// stretched to the height of the glue.
+func Leaders(box Box, g *Glue) *Glue {
+	return LeadersOfKind(LeadersAligned, box, g)
+}
+
+// LeadersOfKind returns a copy of the glue g, which fills the space it
+// occupies with copies of box, arranged as specified by kind.  See
+// [Leaders] for details.
+func LeadersOfKind(kind LeaderKind, box Box, g *Glue) *Glue {
+	res := g.Clone()
+	res.leader = box
+	res.leaderKind = kind
+	return res
+}
+
+// RuleLeaders returns a copy of the glue g, which is filled with a solid
+// rule.  In horizontal lists, the rule extends height above and depth below
+// the baseline.  In vertical lists, the rule has the given width, and the
+// arguments height and depth are not used.
+func RuleLeaders(width, height, depth float64, g *Glue) *Glue {
+	return Leaders(Rule(width, height, depth), g)
+}
+
+// DotLeaders returns glue which can stretch arbitrarily and is filled with
+// dots in the given font.
+func DotLeaders(F *FontInfo) *Glue {
+	dot := Text(F, ".")
+	pitch := F.Size / 2
+	dot.Glyphs.PadTo(pitch)
+	return Leaders(dot, Skip(pitch, 1, 1, 0, 0))
+}
+
+// drawLeaders draws the leaders of g, filling the given width.
+func (g *Glue) drawLeaders(page *builder.Builder, xPos, yPos, width float64) {
+	if g.leader == nil || width <= 0 {
+		return
+	}
+
+	decorate(g.e, page, func() {
+		if rule, ok := g.leader.(*ruleBox); ok {
+			Rule(width, rule.Height, rule.Depth).Draw(page, xPos, yPos)
+			return
+		}
+
+		w := g.leader.Extent().Width
+		for _, x := range leaderPositions(g.leaderKind, xPos, width, w) {
+			g.leader.Draw(page, x, yPos)
+		}
+	})
+}
+
+// drawVLeaders draws the leaders of g in a vertical list, filling the space
+// between yBottom and yBottom+height.
+func (g *Glue) drawVLeaders(page *builder.Builder, xPos, yBottom, height float64) {
+	if g.leader == nil || height <= 0 {
+		return
+	}
+
+	decorate(g.e, page, func() {
+		ext := g.leader.Extent()
+		if rule, ok := g.leader.(*ruleBox); ok {
+			Rule(rule.Width, height, 0).Draw(page, xPos, yBottom)
+			return
+		}
+
+		h := ext.Height + ext.Depth
+		for _, y := range leaderPositions(g.leaderKind, yBottom, height, h) {
+			g.leader.Draw(page, xPos, y+ext.Depth)
+		}
+	})
+}
+
+// leaderPositions returns the start positions of the copies of a leader box
+// of the given size, for filling the interval of the given length.
+func leaderPositions(kind LeaderKind, start, length, size float64) []float64 {
+	if size <= 0 {
+		return nil
+	}
+	n := int(math.Floor(length/size + eps))
+	if n <= 0 {
+		return nil
+	}
+
+	var first, step float64
+	switch kind {
+	case LeadersCentered:
+		first = start + (length-float64(n)*size)/2
+		step = size
+	case LeadersExpanded:
+		gap := (length - float64(n)*size) / float64(n+1)
+		first = start + gap
+		step = size + gap
+	default:
+		first = math.Ceil(start/size-eps) * size
+		n = int(math.Floor((start+length-first)/size + eps))
+		step = size
+		if n <= 0 {
+			return nil
+		}
+	}
+
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = first + float64(i)*step
+	}
+	return res
+}
+
+// fixedLeaders is leader glue which has been set to a fixed width.
+type fixedLeaders struct {
+	glue  *Glue
+	width float64
+}
+
+func (obj *fixedLeaders) Extent() *BoxExtent {
+	ext := obj.glue.leader.Extent()
+	return &BoxExtent{
+		Width:  obj.width,
+		Height: ext.Height,
+		Depth:  ext.Depth,
+	}
+}
+
+func (obj *fixedLeaders) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.glue.drawLeaders(page, xPos, yPos, obj.width)
+}
@@ -0,0 +1,75 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestLeaderPositions(t *testing.T) {
+	cases := []struct {
+		kind          LeaderKind
+		start, length float64
+		want          []float64
+	}{
+		{LeadersAligned, 5, 30, []float64{10, 20}},
+		{LeadersAligned, 0, 30, []float64{0, 10, 20}},
+		{LeadersAligned, 5, 4, nil},
+		{LeadersCentered, 5, 30, []float64{5, 15, 25}},
+		{LeadersCentered, 0, 25, []float64{2.5, 12.5}},
+		{LeadersExpanded, 0, 25, []float64{5.0 / 3, 40.0 / 3}},
+	}
+	for _, c := range cases {
+		got := leaderPositions(c.kind, c.start, c.length, 10)
+		if !slices.EqualFunc(got, c.want, func(a, b float64) bool {
+			return math.Abs(a-b) < 1e-6
+		}) {
+			t.Errorf("%d %g %g: got %v, want %v", c.kind, c.start, c.length, got, c.want)
+		}
+	}
+}
+
+// countBox counts how often it is drawn.
+type countBox struct {
+	BoxExtent
+	n int
+}
+
+func (obj *countBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.n++
+}
+
+func TestLeadersDraw(t *testing.T) {
+	box := &countBox{BoxExtent: BoxExtent{Width: 10, Height: 8, Depth: 2}}
+
+	h := HBoxTo(100, Kern(5), LeadersOfKind(LeadersExpanded, box, Skip(0, 1, 1, 0, 0)))
+	h.Draw(nil, 0, 0)
+	if box.n != 9 {
+		t.Errorf("horizontal: %d copies drawn, want 9", box.n)
+	}
+
+	box.n = 0
+	v := VBoxTo(50, Leaders(box, Skip(0, 1, 1, 0, 0)))
+	v.Draw(nil, 0, 0)
+	if box.n != 5 {
+		t.Errorf("vertical: %d copies drawn, want 5", box.n)
+	}
+}
@@ -24,7 +24,18 @@ import (
 // to the vertical mode list. This triggers the Knuth-Plass line breaking
 // algorithm to find optimal line breaks.
 func (e *Engine) EndParagraph() {
-	// This must match the code in [Engine.VisualiseLineBreaks]
+	// This must match the code in [Engine.DebugLineBreaks]
+
+	if e.measure != nil {
+		e.measure.addParagraph(e, e.hList)
+		e.hList = e.hList[:0]
+		e.hRecords = e.hRecords[:0]
+		e.tabStops = nil
+		e.afterPunct = false
+		e.afterSpace = false
+		e.spaceNext = false
+		return
+	}
 
 	// Gather the material for the line breaker.
 	hList := e.hList
@@ -36,11 +47,15 @@ func (e *Engine) EndParagraph() {
 	// ... and a forced line break.
 	hList = append(hList, &hModePenalty{Penalty: PenaltyForceBreak})
 
+	tabStops := e.tabStops
 	e.hList = e.hList[:0]
+	e.hRecords = e.hRecords[:0]
+	e.tabStops = nil
 	e.afterPunct = false
 	e.afterSpace = false
+	e.spaceNext = false
 
-	lineWidth := &Glue{Length: e.TextWidth}
+	lineWidth := &Glue{Length: e.ColumnWidth()}
 	lineWidth = lineWidth.Minus(e.LeftSkip).Minus(e.RightSkip)
 
 	// Break the paragraph into lines.
@@ -57,30 +72,22 @@ func (e *Engine) EndParagraph() {
 	breaks := br.Run()
 
 	// Add the lines to the vertical list.
+	if e.Tagged && e.vHeading != "" {
+		e.BeginStruct(e.vHeading)
+		e.vHeading = ""
+		defer e.EndStruct()
+	} else if e.Tagged && groupingTypes[e.structCurrent().Type] {
+		e.BeginStruct("P")
+		defer e.EndStruct()
+	}
 	if len(e.vList) > 0 && e.ParSkip != nil {
-		e.vList = append(e.vList, e.ParSkip)
+		e.VAddGlue(e.ParSkip)
 	}
 	prevPos := 0
+	var openRecords []*hRecord
 	for i, pos := range breaks {
 		var currentLine []Box
-		if e.LeftSkip != nil {
-			currentLine = append(currentLine, e.LeftSkip)
-		}
-		for _, item := range hList[prevPos:pos] {
-			switch h := item.(type) {
-			case *Glue:
-				currentLine = append(currentLine, h)
-			case *hModeBox:
-				currentLine = append(currentLine, h.Box)
-			case *hModePenalty:
-				// TODO(voss)
-			default:
-				panic(fmt.Sprintf("unexpected type %T in horizontal mode list", h))
-			}
-		}
-		if e.RightSkip != nil {
-			currentLine = append(currentLine, e.RightSkip)
-		}
+		currentLine, openRecords = e.lineMaterial(hList, prevPos, pos, i+1, tabStops, openRecords)
 
 	skipDiscardible:
 		for prevPos = pos; prevPos < len(hList); prevPos++ {
@@ -105,13 +112,54 @@ func (e *Engine) EndParagraph() {
 			e.VAddPenalty(p)
 		}
 
-		//lineBox := HBoxTo(e.TextWidth, currentLine...)
-		lineBox := makeLine(e.TextWidth, currentLine)
+		//lineBox := HBoxTo(e.ColumnWidth(), currentLine...)
+		lineBox := makeLine(e.ColumnWidth(), currentLine)
 		e.VAddBox(lineBox)
 	}
 }
 
+// lineMaterial returns the boxes for the line made from hList[start:pos],
+// where pos is the position of the line break.  The pre-break material of
+// a penalty at pos is added, the marks of recorded spans are replaced by
+// segment boundaries, LeftSkip and RightSkip are added, and tabs are
+// resolved.  The argument open lists the recorded spans which are open at
+// the start of the line; the function returns the line and the spans still
+// open at the end of the line.
+func (e *Engine) lineMaterial(hList []any, start, pos, lineNo int, tabStops []TabStop, open []*hRecord) ([]Box, []*hRecord) {
+	var material []Box
+	for _, item := range hList[start:pos] {
+		switch h := item.(type) {
+		case *Glue:
+			material = append(material, h)
+		case *hModeBox:
+			material = append(material, h.Box)
+		case *hModePenalty:
+			// TODO(voss)
+		default:
+			panic(fmt.Sprintf("unexpected type %T in horizontal mode list", h))
+		}
+	}
+	if p, ok := hList[pos].(*hModePenalty); ok && p.pre != nil {
+		material = append(material, p.pre)
+	}
+	material, open = hRecordLine(material, lineNo, open)
+
+	var line []Box
+	if e.LeftSkip != nil {
+		line = append(line, e.LeftSkip)
+	}
+	line = append(line, material...)
+	if e.RightSkip != nil {
+		line = append(line, e.RightSkip)
+	}
+	return resolveTabs(line, tabStops), open
+}
+
 func makeLine(width float64, boxes []Box) Box {
+	// The glue is merged into the fixed boxes below, so we check the
+	// original material for overfull lines.
+	overfull := isOverfull(width, boxes)
+
 	xx := horizontalLayout(0, width, boxes...)
 	xx = append(xx, width)
 
@@ -119,6 +167,9 @@ func makeLine(width float64, boxes []Box) Box {
 	var prevText *TextBox
 	gap := 0.0
 	for i, box := range boxes {
+		if g, ok := box.(*Glue); ok && g.leader != nil {
+			box = &fixedLeaders{glue: g, width: xx[i+1] - xx[i]}
+		}
 		ext := box.Extent()
 		if ext.WhiteSpaceOnly {
 			gap += xx[i+1] - xx[i]
@@ -151,5 +202,7 @@ func makeLine(width float64, boxes []Box) Box {
 	if gap != 0 {
 		fixedBoxes = append(fixedBoxes, Kern(gap))
 	}
-	return HBoxTo(width, fixedBoxes...)
+	line := HBoxTo(width, fixedBoxes...).(*hBox)
+	line.overfull = overfull
+	return line
 }
@@ -67,3 +67,33 @@ func TestLineBreaks(t *testing.T) {
 }
 
 const testText = `Call me Ishmael. Some years ago—never mind how long precisely—having little or no money in my purse, and nothing particular to interest me on shore, I thought I would sail about a little and see the watery part of the world. It is a way I have of driving off the spleen and regulating the circulation. Whenever I find myself growing grim about the mouth; whenever it is a damp, drizzly November in my soul; whenever I find myself involuntarily pausing before coffin warehouses, and bringing up the rear of every funeral I meet; and especially whenever my hypos get such an upper hand of me, that it requires a strong moral principle to prevent me from deliberately stepping into the street, and methodically knocking people’s hats off—then, I account it high time to get to sea as soon as I can. This is my substitute for pistol and ball. With a philosophical flourish Cato throws himself upon his sword; I quietly take to the ship. There is nothing surprising in this. If they but knew it, almost all men in their degree, some time or other, cherish very nearly the same feelings towards the ocean with me.`
+
+func TestDebugLineBreaks(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		TextWidth:   200,
+		ParFillSkip: Skip(0, 1, 1, 0, 0),
+	}
+
+	called := false
+	e.SetTabStops(TabStop{Pos: 150, Kind: TabRight})
+	e.HBeginRecord(func(*BoxInfo) { called = true })
+	e.HAddText(fi, "Chapter")
+	e.HEndRecord()
+	e.HAddText(fi, "\t12")
+
+	err := e.DebugLineBreaks(doc.Tree, doc.RM, fi.Font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The debug page does not report recorded spans.
+	if len(e.records) != 0 || called {
+		t.Error("records reported for the debug page")
+	}
+}
@@ -0,0 +1,139 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"fmt"
+	"slices"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/annotation"
+	"seehuhn.de/go/pdf/destination"
+	"seehuhn.de/go/pdf/nametree"
+)
+
+// VNamedDest registers a named destination for the next box added in
+// vertical mode.  The destination shows the page containing the box, with
+// the top of the box at the top of the window.  When the final page has been
+// output, all named destinations are written to the document catalog.
+func (e *Engine) VNamedDest(name string) {
+	root := e.root()
+	e.VRecordNextBox(func(bi *BoxInfo) {
+		if root.dests == nil {
+			root.dests = make(map[string]*BoxInfo)
+		}
+		root.dests[name] = bi
+	})
+}
+
+// VLinkNextBox turns the next box added in vertical mode into a link to the
+// named destination.  The destination may be registered before or after the
+// link.  If the destination is never registered using [Engine.VNamedDest],
+// AppendPages returns an error when the final page is output.
+func (e *Engine) VLinkNextBox(dest string) {
+	e.VRecordNextBox(e.linkTo(dest))
+}
+
+// HAddLink adds a box to the horizontal mode list, which links to the named
+// destination.  The destination may be registered before or after the link,
+// see [Engine.VLinkNextBox].
+func (e *Engine) HAddLink(box Box, dest string) {
+	link := &recordPageLocation{
+		Box: box,
+		e:   e,
+		cb:  []func(*BoxInfo){e.linkTo(dest)},
+	}
+	e.hList = append(e.hList, &hModeBox{
+		Box:   link,
+		width: box.Extent().Width,
+	})
+}
+
+// linkTo returns a callback which adds a link annotation, covering the
+// recorded box, to the current page.
+func (e *Engine) linkTo(dest string) func(*BoxInfo) {
+	e = e.root()
+	return func(bi *BoxInfo) {
+		link := &annotation.Link{
+			Common: annotation.Common{
+				Rect: *bi.BBox, // no border
+			},
+			Destination: &destination.Named{Name: pdf.String(dest)},
+		}
+		if !bi.isRect() {
+			link.QuadPoints = bi.Quad[:]
+		}
+		e.pageAnnots = append(e.pageAnnots, link)
+		if e.linkDests == nil {
+			e.linkDests = make(map[string]bool)
+		}
+		e.linkDests[dest] = true
+	}
+}
+
+// checkLinks returns an error if a link points to a destination which has
+// not been registered.
+func (e *Engine) checkLinks() error {
+	var missing []string
+	for dest := range e.linkDests {
+		if _, ok := e.dests[dest]; !ok {
+			missing = append(missing, dest)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("link to undefined destination %q", missing[0])
+}
+
+// writeNamedDests writes the named destinations to the Dests name tree of the
+// document catalog.  If the catalog already has a Dests name tree, it is left
+// unchanged.
+func (e *Engine) writeNamedDests(rm *pdf.ResourceManager) error {
+	catalog := rm.Out.GetMeta().Catalog
+	names, _ := catalog.Names.(pdf.Dict)
+	if (catalog.Names != nil && names == nil) || names["Dests"] != nil {
+		return nil
+	}
+
+	data := make(map[pdf.Name]pdf.Object, len(e.dests))
+	for name, bi := range e.dests {
+		dest := &destination.XYZ{
+			Page: bi.PageRef,
+			Left: destination.Unset,
+			Top:  bi.BBox.URy,
+			Zoom: destination.Unset,
+		}
+		obj, err := dest.Encode(rm)
+		if err != nil {
+			return err
+		}
+		data[pdf.Name(name)] = obj
+	}
+	ref, err := nametree.WriteMap(rm.Out, data)
+	if err != nil {
+		return err
+	}
+
+	if names == nil {
+		names = pdf.Dict{}
+		catalog.Names = names
+	}
+	names["Dests"] = ref
+	return nil
+}
@@ -0,0 +1,104 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/annotation"
+	"seehuhn.de/go/pdf/destination"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/page"
+)
+
+func TestLinks(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	var pages []*page.Page
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   50,
+		BaseLineSkip: 12,
+		AfterCloseFunc: func(p *page.Page) error {
+			pages = append(pages, p)
+			return nil
+		},
+	}
+
+	here := Text(fi, "here")
+	e.HAddText(fi, "Click ")
+	e.HAddLink(here, "target")
+	e.EndParagraph()
+	e.VAddPenalty(PenaltyForceBreak)
+	e.VNamedDest("target")
+	e.VAddBox(Rule(10, 10, 0))
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := doc.Out.GetMeta().Catalog.Names.(pdf.Dict)
+	if names["Dests"] == nil {
+		t.Error("named destinations not written")
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if len(pages[0].Annots) != 1 || len(pages[1].Annots) != 0 {
+		t.Fatal("wrong number of annotations")
+	}
+	link, ok := pages[0].Annots[0].(*annotation.Link)
+	if !ok {
+		t.Fatalf("expected link annotation, got %T", pages[0].Annots[0])
+	}
+	if dest, ok := link.Destination.(*destination.Named); !ok || string(dest.Name) != "target" {
+		t.Errorf("wrong destination %v", link.Destination)
+	}
+	if w := link.Rect.URx - link.Rect.LLx; math.Abs(w-here.Extent().Width) > 1e-6 {
+		t.Errorf("wrong link width %g", w)
+	}
+	if bi := e.dests["target"]; bi == nil || bi.PageNo != 2 {
+		t.Error("destination not placed on page 2")
+	}
+}
+
+func TestLinkUndefinedDest(t *testing.T) {
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:   document.A4,
+		TextWidth:  300,
+		TextHeight: 50,
+	}
+
+	e.VNamedDest("target")
+	e.VAddBox(Rule(10, 10, 0))
+	e.VLinkNextBox("tagret")
+	e.VAddBox(Rule(10, 10, 0))
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err == nil {
+		t.Error("link to undefined destination not detected")
+	}
+}
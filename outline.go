@@ -0,0 +1,141 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"strconv"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/destination"
+	"seehuhn.de/go/pdf/outline"
+)
+
+// Heading is an entry in the document outline.
+type Heading struct {
+	// Level is the nesting depth of the heading.  Headings with larger
+	// levels are nested inside the preceding heading with a smaller level.
+	Level int
+
+	// Title is the text shown in the outline.
+	Title string
+
+	// Info gives the location of the heading, once it has been placed on a
+	// page.
+	Info *BoxInfo
+}
+
+// pageNo returns the page number of the heading, or 0 if the heading has
+// not been placed on a page.
+func (h *Heading) pageNo() int {
+	if h.Info == nil {
+		return 0
+	}
+	return h.Info.PageNo
+}
+
+// VMarkHeading marks the next box added in vertical mode as a heading.
+// When the final page has been output, the headings are used to write the
+// document outline.
+//
+// If Tagged is set, the next paragraph, or the next box if no paragraph is
+// finished first, is tagged as a heading element "H1" to "H6", depending on
+// level.  No element is added if the current structure element is already a
+// heading.
+func (e *Engine) VMarkHeading(level int, title string) {
+	h := &Heading{
+		Level: level,
+		Title: title,
+	}
+	root := e.root()
+	root.headings = append(root.headings, h)
+	if e.CrossRefs != nil {
+		e.CrossRefs.headings = append(e.CrossRefs.headings, h)
+	}
+	e.VRecordNextBox(func(bi *BoxInfo) {
+		h.Info = bi
+	})
+	if e.Tagged && !isHeadingType(e.structCurrent().Type) {
+		e.vHeading = "H" + strconv.Itoa(min(max(level, 1), 6))
+	}
+}
+
+// isHeadingType reports whether typ is one of the standard structure types
+// for headings.
+func isHeadingType(typ string) bool {
+	switch typ {
+	case "H", "H1", "H2", "H3", "H4", "H5", "H6":
+		return true
+	}
+	return false
+}
+
+// Headings returns the headings marked so far, in document order.
+func (e *Engine) Headings() []*Heading {
+	return e.headings
+}
+
+// makeOutline arranges the headings which have been placed on a page into a
+// document outline.
+func (e *Engine) makeOutline() *outline.Outline {
+	type open struct {
+		level int
+		item  *outline.Item
+	}
+
+	res := &outline.Outline{}
+	var stack []open
+	for _, h := range e.headings {
+		if h.Info == nil {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		var item *outline.Item
+		if len(stack) == 0 {
+			item = res.AddItem(h.Title)
+		} else {
+			item = stack[len(stack)-1].item.AddChild(h.Title)
+		}
+		item.Destination = &destination.XYZ{
+			Page: h.Info.PageRef,
+			Left: destination.Unset,
+			Top:  h.Info.BBox.URy,
+			Zoom: destination.Unset,
+		}
+		stack = append(stack, open{level: h.Level, item: item})
+	}
+	return res
+}
+
+// writeOutline writes the document outline and registers it in the
+// document catalog.  If the catalog already has an outline, it is left
+// unchanged.
+func (e *Engine) writeOutline(rm *pdf.ResourceManager) error {
+	catalog := rm.Out.GetMeta().Catalog
+	if catalog.Outlines != 0 {
+		return nil
+	}
+
+	ref, err := rm.Store(e.makeOutline())
+	if err != nil {
+		return err
+	}
+	catalog.Outlines = ref
+	return nil
+}
@@ -0,0 +1,70 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf/destination"
+	"seehuhn.de/go/pdf/document"
+)
+
+func TestOutline(t *testing.T) {
+	doc, _ := newTestDoc(t)
+
+	e := &Engine{
+		PageSize:   document.A4,
+		TextHeight: 30,
+	}
+	for i, level := range []int{1, 2, 2, 1} {
+		e.VMarkHeading(level, string(rune('A'+i)))
+		e.VAddBox(Rule(10, 20, 0))
+		e.VAddPenalty(0)
+	}
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Out.GetMeta().Catalog.Outlines == 0 {
+		t.Error("outline not written")
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hh := e.Headings()
+	for i, h := range hh {
+		if h.Info == nil || h.Info.PageNo != i+1 {
+			t.Errorf("heading %d: wrong location", i)
+		}
+	}
+
+	out := e.makeOutline()
+	if len(out.Items) != 2 || len(out.Items[0].Children) != 2 {
+		t.Fatal("wrong outline structure")
+	}
+	b := out.Items[0].Children[1]
+	if b.Title != "C" {
+		t.Errorf("expected title C, got %q", b.Title)
+	}
+	dest, ok := b.Destination.(*destination.XYZ)
+	if !ok || dest.Page != hh[2].Info.PageRef || dest.Top != 72+30 {
+		t.Errorf("wrong destination %v", b.Destination)
+	}
+}
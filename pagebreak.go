@@ -18,6 +18,7 @@ package layout
 
 import (
 	"math"
+	"slices"
 
 	"seehuhn.de/go/pdf"
 	"seehuhn.de/go/pdf/graphics/content"
@@ -37,9 +38,37 @@ func (e *Engine) MakeVTop() Box {
 // AppendPages breaks the vertical mode list into pages and appends them
 // to the page tree. If final is false, some material may be held back
 // to allow for better page breaks when more content is added.
+//
+// By default, the best break is chosen for each page in turn.  If final is
+// true and OptimalPageBreaks is set, the breaks for all remaining material
+// are chosen together, so that the total demerits over all pages are
+// minimised.  Groups kept together are honoured, since they only allow
+// breaks with infinite penalty.  Material set in multiple columns, material
+// containing splittable boxes, and material following a change of page
+// template are always broken page by page.
+//
+// If final is true, the document outline is written for headings marked
+// using [Engine.VMarkHeading], and named destinations registered using
+// [Engine.VNamedDest] are added to the document catalog.  If Tagged is set,
+// the structure tree is written, too.  An error is returned if a link points
+// to a destination which was never registered.
 func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, final bool) error {
+	if final && e.OptimalPageBreaks {
+		e.vConsumeMarkers()
+		if e.pageColumns <= 1 && vSectionEnd(e.vList) == len(e.vList) &&
+			!slices.ContainsFunc(e.vList, isSplitBox) {
+			e.vPlan = e.vOptimalBreaks(e.vList, e.pageTextHeight())
+		}
+	}
+	defer func() { e.vPlan = nil }()
+
 	for len(e.vList) > 0 {
-		if !final && (e.vTotalHeight() < 2*e.TextHeight || len(e.vList) < 2) {
+		e.vConsumeMarkers()
+		if len(e.vList) == 0 {
+			break
+		}
+		pageHeight := e.pageTextHeight() * float64(max(e.pageColumns, 1))
+		if !final && (e.vTotalHeight() < 2*pageHeight || len(e.vList) < 2) {
 			break
 		}
 
@@ -51,7 +80,7 @@ func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, fin
 			}
 		}
 
-		vbox := e.makePage()
+		vbox := e.makePage(final)
 
 		if len(e.records) > 0 {
 			panic("unexpected records")
@@ -61,16 +90,35 @@ func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, fin
 		b := builder.New(content.Page, nil, pdf.GetVersion(rm.Out))
 
 		if e.BeforePageFunc != nil {
-			err := e.BeforePageFunc(e.PageNumber, b)
+			err := e.artifact(b, "Pagination", func() error {
+				return e.BeforePageFunc(e.PageNumber, b)
+			})
 			if err != nil {
 				return err
 			}
 		}
 
-		vbox.Draw(b, 72, 72) // TODO(voss): make the margins configurable
+		tmpl := e.pageTemplate
+		xPos, yPos := 72.0, 72.0
+		if tmpl != nil {
+			xPos = tmpl.TrimBox.LLx + tmpl.TextX
+			yPos = tmpl.TrimBox.LLy + tmpl.TextY
+		}
+		if e.PageSnapshotFunc != nil {
+			e.PageSnapshotFunc(e.PageNumber, newSnapshot(vbox, xPos, yPos))
+		}
+		vbox.Draw(b, xPos, yPos)
+		if tmpl != nil && tmpl.CropMarks > 0 {
+			e.artifact(b, "Pagination", func() error {
+				tmpl.drawCropMarks(b)
+				return nil
+			})
+		}
 
 		if e.AfterPageFunc != nil {
-			err := e.AfterPageFunc(e.PageNumber, b)
+			err := e.artifact(b, "Pagination", func() error {
+				return e.AfterPageFunc(e.PageNumber, b)
+			})
 			if err != nil {
 				return err
 			}
@@ -87,6 +135,9 @@ func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, fin
 			Resources: b.Resources,
 			Contents:  []page.Segment{seg},
 		}
+		if tmpl != nil {
+			tmpl.setBoxes(p)
+		}
 
 		pageRef := tree.Out.Alloc()
 		if len(e.records) > 0 {
@@ -101,6 +152,11 @@ func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, fin
 			}
 			e.records = e.records[:0]
 		}
+		e.structFinishPage(tree.Out, p, pageRef)
+		if len(e.pageAnnots) > 0 {
+			p.Annots = append(p.Annots, e.pageAnnots...)
+			e.pageAnnots = nil
+		}
 
 		if e.AfterCloseFunc != nil {
 			err := e.AfterCloseFunc(p)
@@ -115,50 +171,180 @@ func (e *Engine) AppendPages(tree *pagetree.Writer, rm *pdf.ResourceManager, fin
 		}
 	}
 
+	if final {
+		err := e.checkLinks()
+		if err != nil {
+			return err
+		}
+	}
+	if final && len(e.headings) > 0 {
+		err := e.writeOutline(rm)
+		if err != nil {
+			return err
+		}
+	}
+	if final && len(e.dests) > 0 {
+		err := e.writeNamedDests(rm)
+		if err != nil {
+			return err
+		}
+	}
+	if final && e.Tagged && e.structRoot != nil {
+		return e.writeStructTree(rm)
+	}
 	return nil
 }
 
-func (e *Engine) makePage() Box {
-	height := e.TextHeight
+func (e *Engine) makePage(final bool) Box {
+	pageHeight := e.pageTextHeight()
+	height := pageHeight
 
-	cand := e.vGetCandidates(height)
-	bestPos := -1
-	bestCost := math.Inf(+1)
-	for _, c := range cand {
-		cost := c.badness + float64(c.penalty)
-		if cost <= bestCost {
-			bestCost = cost
-			bestPos = c.pos
+	if len(e.vPlan) > 0 {
+		pos := e.vPlan[0]
+		e.vPlan = e.vPlan[1:]
+		col, rest := e.vColumnAt(e.vList, pos, e.vTopSkip(e.vList, e.TopSkip))
+		e.vList = rest
+		return VBoxTo(height, col...)
+	}
+
+	var parts []Box
+	full := false
+	for len(e.vList) > 0 && !full {
+		e.vConsumeColumnChanges()
+		if len(e.vList) == 0 || isPageTemplateChange(e.vList[0]) {
+			break
+		}
+
+		n := max(e.pageColumns, 1)
+		end := vSectionEnd(e.vList)
+		section := e.vList[:end]
+		atTop := len(parts) == 0
+
+		// At the top of the page, columns start at TopSkip.  Further down,
+		// the first column starts with the material from the list and the
+		// other columns are aligned to this.  In grid mode, all columns
+		// start on the next grid line instead.
+		skip, keepLeading := e.TopSkip, false
+		if !atTop {
+			skip, keepLeading = vFirstBaseline(section), true
+			if e.BaselineGrid {
+				top := pageHeight - height
+				skip, keepLeading = e.gridCeil(top+skip)-top, false
+				section = vDropDiscardible(section)
+			}
+		}
+
+		// If the section ends on this page, balance the columns and
+		// continue with the next section below.  A change of page template
+		// ends the page, and single columns are then left unbalanced.
+		sectionDone := end < len(e.vList) || final
+		if sectionDone && (n > 1 || end < len(e.vList) && !isPageTemplateChange(e.vList[end])) {
+			if h, ok := e.vBalance(section, n, height, skip, keepLeading); ok {
+				cols, _, _ := e.vFillColumns(section, n, h, skip, keepLeading)
+				e.vList = e.vList[end:]
+				part := e.vColumnBox(cols, h)
+				parts = append(parts, part)
+				height -= h + part.Extent().Depth
+				continue
+			}
+		}
+
+		cols, rest, overfull := e.vFillColumns(section, n, height, skip, keepLeading)
+		if overfull && !atTop {
+			// not enough space left, continue on the next page
+			break
+		}
+		// The remaining material is the tail of the section, except that
+		// its first box may be the remainder of a split box.
+		e.vList = e.vList[end-len(rest):]
+		if len(rest) > 0 {
+			if sb, ok := rest[0].(*splitBox); ok && e.vList[0] != Box(sb) {
+				e.vList[0] = sb
+			}
+		}
+		parts = append(parts, e.vColumnBox(cols, height))
+		full = true
+	}
+
+	if len(parts) == 1 && full {
+		return parts[0]
+	}
+	return VBoxTo(pageHeight, parts...)
+}
+
+// vFillColumn takes the material for a single column of the given height
+// from the start of vList.  If skip is non-negative, a kern is inserted so
+// that the first baseline is placed skip below the top of the column.  The
+// function returns the column contents, the remaining material, and whether
+// the column is overfull.
+func (e *Engine) vFillColumn(vList []Box, height, skip float64) ([]Box, []Box, bool) {
+	topSkip := e.vTopSkip(vList, skip)
+
+	cand := vBreakCandidates(vList, height, topSkip, e.BottomGlue)
+	best := vBestCandidate(cand)
+
+	// If a splittable box extends beyond the bottom of the column, check
+	// whether splitting the box gives a better break.
+	if k, avail := vFindSplit(vList, height, topSkip); k >= 0 {
+		if split := e.vSplitAt(vList, k, avail); split != nil {
+			splitSkip := e.vTopSkip(split, skip)
+			cand = vBreakCandidates(split, height, splitSkip, e.BottomGlue)
+			if splitBest := vBestCandidate(cand); splitBest.pos > k {
+				vList, topSkip, best = split, splitSkip, splitBest
+			}
 		}
 	}
 
-	ext0 := e.vList[0].Extent()
-	topSkip := e.TopSkip - ext0.Height
-	if topSkip < 0 {
-		topSkip = 0
+	col, rest := e.vColumnAt(vList, best.pos, topSkip)
+	return col, rest, best.overfull
+}
+
+// vBestCandidate returns the candidate with the lowest cost.  In case of ties,
+// the last candidate is used.
+func vBestCandidate(cand []vCandidate) vCandidate {
+	best := cand[0]
+	for _, c := range cand {
+		if c.badness+float64(c.penalty) <= best.badness+float64(best.penalty) {
+			best = c
+		}
 	}
+	return best
+}
 
+// vColumnAt breaks vList at position pos.  The function returns the contents
+// of the column above the break, and the remaining material.
+func (e *Engine) vColumnAt(vList []Box, pos int, topSkip float64) ([]Box, []Box) {
 	var res []Box
 	if topSkip > 0 {
 		res = append(res, Kern(topSkip))
 	}
-	res = append(res, e.vList[:bestPos]...)
+	res = append(res, vList[:pos]...)
 	if e.BottomGlue != nil {
 		res = append(res, e.BottomGlue)
 	}
+	return res, vDropDiscardible(vList[pos:])
+}
 
-	e.vList = e.vList[bestPos:]
-	for len(e.vList) > 0 && vDiscardible(e.vList[0]) {
-		e.vList = e.vList[1:]
+// vTopSkip returns the space to insert above the first item of vList, so
+// that the first baseline is placed skip below the top of the column.  If
+// skip is negative, no space is inserted.
+func (e *Engine) vTopSkip(vList []Box, skip float64) float64 {
+	if skip < 0 || len(vList) == 0 {
+		return 0
 	}
-
-	return VBoxTo(height, res...)
+	h := vList[0].Extent().Height
+	if e.BaselineGrid && e.BaseLineSkip > 0 && h > skip {
+		// move tall boxes down to the next grid line
+		skip += math.Ceil((h-skip)/e.BaseLineSkip-eps) * e.BaseLineSkip
+	}
+	return max(skip-h, 0)
 }
 
 type vCandidate struct {
-	pos     int
-	badness float64
-	penalty penalty
+	pos      int
+	badness  float64
+	penalty  penalty
+	overfull bool
 }
 
 func (e *Engine) vGetCandidates(height float64) []vCandidate {
@@ -166,23 +352,28 @@ func (e *Engine) vGetCandidates(height float64) []vCandidate {
 		return nil
 	}
 
-	ext0 := e.vList[0].Extent()
-	topSkip := e.TopSkip - ext0.Height
-	if topSkip < 0 {
-		topSkip = 0
+	return vBreakCandidates(e.vList, height, e.vTopSkip(e.vList, e.TopSkip), e.BottomGlue)
+}
+
+// vBreakCandidates returns the possible page breaks for a page of the given
+// height, filled from the start of vList.  The topSkip space is added
+// above the first item, and the bottom glue (if any) below the last item.
+func vBreakCandidates(vList []Box, height, topSkip float64, bottom *Glue) []vCandidate {
+	if len(vList) == 0 {
+		return nil
 	}
 
 	total := &Glue{
 		Length: topSkip,
 	}
-	total.Add(e.BottomGlue)
+	total.Add(bottom)
 
 	var res []vCandidate
 	prevDepth := 0.0
-	for i := 0; i <= len(e.vList); i++ {
+	for i := 0; i <= len(vList); i++ {
 		var box Box
-		if i < len(e.vList) {
-			box = e.vList[i]
+		if i < len(vList) {
+			box = vList[i]
 		}
 
 		minHeight := total.minLength()
@@ -193,7 +384,7 @@ func (e *Engine) vGetCandidates(height float64) []vCandidate {
 
 		penalty, isPenalty := box.(penalty)
 
-		if e.vCanBreak(i) && !math.IsInf(float64(penalty), +1) {
+		if vCanBreak(vList, i) && !math.IsInf(float64(penalty), +1) {
 			var badness float64
 
 			if minHeight > height {
@@ -222,9 +413,10 @@ func (e *Engine) vGetCandidates(height float64) []vCandidate {
 			}
 
 			res = append(res, vCandidate{
-				pos:     i,
-				badness: badness,
-				penalty: penalty,
+				pos:      i,
+				badness:  badness,
+				penalty:  penalty,
+				overfull: minHeight > height,
 			})
 
 			if math.IsInf(float64(penalty), -1) {
@@ -251,19 +443,19 @@ func (e *Engine) vGetCandidates(height float64) []vCandidate {
 
 // vCanBreak returns true if the vertical list can be broken before the
 // element at position pos.
-func (e *Engine) vCanBreak(pos int) bool {
-	if pos == len(e.vList) {
+func vCanBreak(vList []Box, pos int) bool {
+	if pos == len(vList) {
 		return true
-	} else if pos < 1 || pos > len(e.vList) {
+	} else if pos < 1 || pos > len(vList) {
 		return false
 	}
 
-	switch obj := e.vList[pos].(type) {
+	switch obj := vList[pos].(type) {
 	case *Glue: // before glue, if following a non-discardible item
-		return !vDiscardible(e.vList[pos-1])
+		return !vDiscardible(vList[pos-1])
 	case Kern: // before kern, if followed by glue
-		if pos < len(e.vList)-1 {
-			_, followedByGlue := e.vList[pos+1].(*Glue)
+		if pos < len(vList)-1 {
+			_, followedByGlue := vList[pos+1].(*Glue)
 			return followedByGlue
 		}
 		return false
@@ -278,6 +470,19 @@ func vDiscardible(box Box) bool {
 	return box.Extent().WhiteSpaceOnly
 }
 
+// vDropDiscardible removes discardible items from the start of vList.
+// Changes of the column layout or the page template are never discarded.
+func vDropDiscardible(vList []Box) []Box {
+	for len(vList) > 0 && vDiscardible(vList[0]) {
+		switch vList[0].(type) {
+		case columnChange, *pageTemplateChange:
+			return vList
+		}
+		vList = vList[1:]
+	}
+	return vList
+}
+
 // vTotalHeight returns the total height plus depth of the vertical list.
 func (e *Engine) vTotalHeight() float64 {
 	var height float64
@@ -19,6 +19,9 @@ package layout
 import (
 	"math"
 	"testing"
+
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/content/builder"
 )
 
 func TestVBreakCandidates1(t *testing.T) {
@@ -127,3 +130,161 @@ func TestVBreakCandidates2(t *testing.T) {
 		t.Fatalf("expected break with penalty 456, got %f", cand[0].penalty)
 	}
 }
+
+func TestOptimalPageBreaks(t *testing.T) {
+	line := func() Box {
+		return &ruleBox{
+			BoxExtent: BoxExtent{
+				Width:  10,
+				Height: 10,
+			},
+		}
+	}
+	vList := []Box{
+		line(),
+		penalty(0),
+		line(),
+		penalty(50),
+		line(),
+	}
+	e := &Engine{
+		TextHeight: 20,
+		BottomGlue: &Glue{Stretch: glueAmount{Val: 10}},
+		vList:      vList,
+	}
+
+	// Page by page, the break after the second line looks best.  This
+	// leaves a single line on the second page, and breaking after the
+	// first line is better overall.
+	breaks := e.vOptimalBreaks(vList, e.TextHeight)
+	if len(breaks) != 2 || breaks[0] != 1 || breaks[1] != 3 {
+		t.Errorf("expected breaks [1 3], got %v", breaks)
+	}
+
+	e.vPlan = breaks
+	page := e.makePage(true)
+	if h := page.Extent().Height; h != 20 {
+		t.Errorf("expected page height 20, got %g", h)
+	}
+	if len(e.vList) != 3 {
+		t.Errorf("expected 3 items left, got %d", len(e.vList))
+	}
+}
+
+func TestOptimalPageBreaksSplittable(t *testing.T) {
+	doc, _ := newTestDoc(t)
+	var pages int
+	e := &Engine{
+		PageSize:          document.A4,
+		TextWidth:         100,
+		TextHeight:        30,
+		OptimalPageBreaks: true,
+		AfterPageFunc: func(int, *builder.Builder) error {
+			pages++
+			return nil
+		},
+	}
+	e.VAddSplittable(VBox(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0),
+	), nil)
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The box does not fit on one page and must be split.
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+}
+
+func TestKeep(t *testing.T) {
+	line := Rule(10, 8, 2)
+	skip := Skip(4, 1, 0, 0, 0)
+
+	e := &Engine{}
+	e.VAddBox(line)
+	e.VAddGlue(skip)
+	e.VKeepWithNext(1)
+	e.VAddBox(line) // heading
+	e.VAddGlue(skip)
+	e.VAddBox(line)
+	e.VAddPenalty(0)
+	e.VAddBox(line)
+	e.VBeginKeep()
+	e.VAddGlue(skip)
+	e.VAddBox(line)
+	e.VAddPenalty(0)
+	e.VAddBox(line)
+	e.VEndKeep()
+	e.VAddGlue(skip)
+	e.VAddBox(line)
+
+	var breaks []int
+	for pos := range e.vList {
+		if vCanBreak(e.vList, pos) {
+			breaks = append(breaks, pos)
+		}
+	}
+	// allowed: before the heading, at the first penalty, before the group,
+	// and after the group
+	expected := []int{1, 6, 8, 12}
+	if len(breaks) != len(expected) {
+		t.Fatalf("expected breaks %v, got %v", expected, breaks)
+	}
+	for i := range breaks {
+		if breaks[i] != expected[i] {
+			t.Fatalf("expected breaks %v, got %v", expected, breaks)
+		}
+	}
+}
+
+func TestBaselineGrid(t *testing.T) {
+	e := &Engine{
+		TopSkip:      10,
+		BaseLineSkip: 12,
+		BaselineGrid: true,
+	}
+	e.VAddBox(Rule(10, 8, 2))
+	e.VAddGlue(Skip(5, 3, 0, 0, 0))
+	e.VAddBox(Rule(10, 14, 4)) // a heading
+	e.VAddBox(Rule(10, 8, 2))
+
+	var baselines []float64
+	y := 0.0
+	for _, box := range e.vList {
+		ext := box.Extent()
+		y += ext.Height
+		if !ext.WhiteSpaceOnly {
+			baselines = append(baselines, y)
+		}
+		y += ext.Depth
+		if getStretch(box, 0) != 0 {
+			t.Errorf("unexpected stretchable glue in grid mode")
+		}
+	}
+
+	expected := []float64{8, 32, 44}
+	if len(baselines) != len(expected) {
+		t.Fatalf("expected baselines %v, got %v", expected, baselines)
+	}
+	for i := range baselines {
+		if math.Abs(baselines[i]-expected[i]) > 1e-6 {
+			t.Fatalf("expected baselines %v, got %v", expected, baselines)
+		}
+	}
+
+	// At the top of the page, the tall box is moved down to the next
+	// grid line.
+	if skip := e.vTopSkip(e.vList[3:], e.TopSkip); skip != 8 {
+		t.Errorf("expected top skip 8, got %g", skip)
+	}
+}
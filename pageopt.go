@@ -0,0 +1,96 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	vInfBad   = 1e4 // badness of pages which cannot be stretched enough
+	vAwfulBad = 1e5 // badness of overfull pages
+)
+
+// vOptimalBreaks chooses page breaks for all of vList, such that the total
+// demerits over all pages are minimal.  This uses dynamic programming over
+// all feasible breaks.  For every page, the result gives the position of
+// the break relative to the start of the page.
+func (e *Engine) vOptimalBreaks(vList []Box, height float64) []int {
+	n := len(vList)
+
+	// For every position where a page can start, we record the best way
+	// to reach this position.
+	type vNode struct {
+		demerits float64
+		prev     int
+		pos      int // break position, relative to prev
+	}
+	nodes := make([]vNode, n+1)
+	for i := range nodes {
+		nodes[i].demerits = math.Inf(+1)
+	}
+	nodes[0].demerits = 0
+
+	for start := 0; start < n; start++ {
+		a := nodes[start]
+		if math.IsInf(a.demerits, +1) {
+			continue
+		}
+
+		page := vList[start:]
+		cand := vBreakCandidates(page, height, e.vTopSkip(page, e.TopSkip), e.BottomGlue)
+		for _, c := range cand {
+			d := a.demerits + vDemerits(c)
+			next := n - len(vDropDiscardible(page[c.pos:]))
+			if d < nodes[next].demerits {
+				nodes[next] = vNode{
+					demerits: d,
+					prev:     start,
+					pos:      c.pos,
+				}
+			}
+		}
+	}
+	if math.IsInf(nodes[n].demerits, +1) {
+		return nil
+	}
+
+	var breaks []int
+	for i := n; i > 0; i = nodes[i].prev {
+		breaks = append(breaks, nodes[i].pos)
+	}
+	slices.Reverse(breaks)
+	return breaks
+}
+
+// vDemerits returns the demerits for a page ending at the given candidate
+// break.  This uses the same formula as the line breaker.
+func vDemerits(c vCandidate) float64 {
+	b := min(c.badness, vInfBad)
+	if c.overfull {
+		b = vAwfulBad
+	}
+
+	p := float64(c.penalty)
+	if p >= 0 {
+		return pow2(1 + b + p)
+	} else if p != PenaltyForceBreak {
+		return pow2(1+b) - pow2(p)
+	}
+	return pow2(1 + b)
+}
@@ -0,0 +1,184 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/page"
+)
+
+// PageTemplate describes the geometry of a page.
+type PageTemplate struct {
+	// TrimBox gives the size and position of the finished page, after
+	// trimming.
+	TrimBox *pdf.Rectangle
+
+	// Bleed is the width of the area around the trim box into which printed
+	// material may extend.
+	Bleed float64
+
+	// CropMarks, if positive, is the length of the crop marks drawn at the
+	// corners of the trim box.  The marks are placed outside the bleed area.
+	CropMarks float64
+
+	// TextX and TextY give the position of the lower left corner of the text
+	// area, relative to the lower left corner of the trim box.
+	TextX, TextY float64
+
+	// TextWidth and TextHeight, if positive, replace Engine.TextWidth and
+	// Engine.TextHeight on pages using this template.
+	TextWidth, TextHeight float64
+
+	// Rotate specifies how the page is rotated when displayed or printed.
+	Rotate page.Rotation
+}
+
+// minMarkOffset is the minimum distance between crop marks and the trim box.
+const minMarkOffset = 3
+
+// SetPageTemplate changes the page template for the following material.
+// Material added after this call starts on a new page, and the template
+// is used for all pages from there on.  If tmpl is nil, pages of size
+// PageSize are used.  Paragraphs which are finished after this call are
+// typeset to the text width of the new template.
+func (e *Engine) SetPageTemplate(tmpl *PageTemplate) {
+	e.template = tmpl
+	e.vList = append(e.vList, &pageTemplateChange{tmpl: tmpl})
+}
+
+// pageTemplateChange marks a change of the page template in the vertical
+// list.
+type pageTemplateChange struct {
+	tmpl *PageTemplate
+}
+
+func (obj *pageTemplateChange) Extent() *BoxExtent {
+	return &BoxExtent{WhiteSpaceOnly: true}
+}
+
+func (obj *pageTemplateChange) Draw(page *builder.Builder, xPos, yPos float64) {
+	// pass
+}
+
+func isPageTemplateChange(box Box) bool {
+	_, ok := box.(*pageTemplateChange)
+	return ok
+}
+
+// vConsumeMarkers processes changes of the column layout and of the page
+// template at the start of the vertical list.
+func (e *Engine) vConsumeMarkers() {
+	for len(e.vList) > 0 {
+		e.vConsumeColumnChanges()
+		change, ok := e.vList[0].(*pageTemplateChange)
+		if !ok {
+			break
+		}
+		e.pageTemplate = change.tmpl
+		e.vList = e.vList[1:]
+	}
+}
+
+// pageTextHeight returns the height of the text area on the current page.
+func (e *Engine) pageTextHeight() float64 {
+	if e.pageTemplate != nil && e.pageTemplate.TextHeight > 0 {
+		return e.pageTemplate.TextHeight
+	}
+	return e.TextHeight
+}
+
+// newTextHeight returns the height of the text area for new material, taking
+// the page template set by [Engine.SetPageTemplate] into account.
+func (e *Engine) newTextHeight() float64 {
+	if e.template != nil && e.template.TextHeight > 0 {
+		return e.template.TextHeight
+	}
+	return e.TextHeight
+}
+
+// pageTextWidth returns the width of the text area on the current page.
+func (e *Engine) pageTextWidth() float64 {
+	if e.pageTemplate != nil && e.pageTemplate.TextWidth > 0 {
+		return e.pageTemplate.TextWidth
+	}
+	return e.TextWidth
+}
+
+func (tmpl *PageTemplate) markOffset() float64 {
+	return max(tmpl.Bleed, minMarkOffset)
+}
+
+// setBoxes sets the page boundaries of p.
+func (tmpl *PageTemplate) setBoxes(p *page.Page) {
+	trim := tmpl.TrimBox
+	bleed := grow(trim, tmpl.Bleed)
+	media := bleed
+	if tmpl.CropMarks > 0 {
+		media = grow(trim, tmpl.markOffset()+tmpl.CropMarks)
+	}
+
+	p.MediaBox = media
+	p.CropBox = media
+	p.BleedBox = bleed
+	p.TrimBox = trim
+	p.Rotate = tmpl.Rotate
+}
+
+// drawCropMarks draws crop marks at the corners of the trim box.
+func (tmpl *PageTemplate) drawCropMarks(page *builder.Builder) {
+	if tmpl.CropMarks <= 0 {
+		return
+	}
+
+	trim := tmpl.TrimBox
+	d := tmpl.markOffset()
+	l := tmpl.CropMarks
+
+	page.PushGraphicsState()
+	page.SetStrokeColor(color.Black)
+	page.SetLineWidth(0.25)
+	for _, x := range []float64{trim.LLx, trim.URx} {
+		for _, y := range []float64{trim.LLy, trim.URy} {
+			sx := 1.0
+			if x == trim.LLx {
+				sx = -1
+			}
+			sy := 1.0
+			if y == trim.LLy {
+				sy = -1
+			}
+			page.MoveTo(x+sx*d, y)
+			page.LineTo(x+sx*(d+l), y)
+			page.MoveTo(x, y+sy*d)
+			page.LineTo(x, y+sy*(d+l))
+		}
+	}
+	page.Stroke()
+	page.PopGraphicsState()
+}
+
+// grow returns a copy of r, enlarged by d on all sides.
+func grow(r *pdf.Rectangle, d float64) *pdf.Rectangle {
+	return &pdf.Rectangle{
+		LLx: r.LLx - d,
+		LLy: r.LLy - d,
+		URx: r.URx + d,
+		URy: r.URy + d,
+	}
+}
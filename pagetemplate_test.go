@@ -0,0 +1,131 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/page"
+)
+
+func TestPageTemplate(t *testing.T) {
+	doc, _ := newTestDoc(t)
+
+	var pages []*page.Page
+	e := &Engine{
+		PageSize:   document.A4,
+		TextHeight: 100,
+		AfterCloseFunc: func(p *page.Page) error {
+			pages = append(pages, p)
+			return nil
+		},
+	}
+	landscape := &PageTemplate{
+		TrimBox:    &pdf.Rectangle{URx: 842, URy: 595},
+		Bleed:      9,
+		CropMarks:  18,
+		TextX:      72,
+		TextY:      72,
+		TextHeight: 50,
+	}
+
+	e.VAddBox(Rule(10, 10, 0))
+	e.SetPageTemplate(landscape)
+	for range 4 {
+		e.VAddBox(Rule(10, 20, 0))
+		e.VAddPenalty(0)
+	}
+	e.SetPageTemplate(nil)
+	e.VAddBox(Rule(10, 10, 0))
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 4 {
+		t.Fatalf("expected 4 pages, got %d", len(pages))
+	}
+	for _, i := range []int{0, 3} {
+		p := pages[i]
+		if !p.MediaBox.Equal(document.A4) || p.TrimBox != nil {
+			t.Errorf("page %d: unexpected page boxes", i+1)
+		}
+	}
+	for _, i := range []int{1, 2} {
+		p := pages[i]
+		if !p.TrimBox.Equal(landscape.TrimBox) {
+			t.Errorf("page %d: wrong trim box %s", i+1, p.TrimBox)
+		}
+		bleed := &pdf.Rectangle{LLx: -9, LLy: -9, URx: 851, URy: 604}
+		if !p.BleedBox.Equal(bleed) {
+			t.Errorf("page %d: wrong bleed box %s", i+1, p.BleedBox)
+		}
+		media := &pdf.Rectangle{LLx: -27, LLy: -27, URx: 869, URy: 622}
+		if !p.MediaBox.Equal(media) || !p.CropBox.Equal(media) {
+			t.Errorf("page %d: wrong media box %s", i+1, p.MediaBox)
+		}
+	}
+}
+
+func TestPageTemplateTextWidth(t *testing.T) {
+	e := &Engine{
+		TextWidth:    200,
+		TextHeight:   100,
+		ColumnSep:    10,
+		BaseLineSkip: 10,
+	}
+	wide := &PageTemplate{
+		TrimBox:   &pdf.Rectangle{URx: 842, URy: 595},
+		TextWidth: 410,
+	}
+
+	e.SetPageTemplate(wide)
+	e.SetColumns(2)
+	if w := e.ColumnWidth(); w != 200 {
+		t.Errorf("expected column width 200, got %g", w)
+	}
+	for range 4 {
+		e.VAddBox(Rule(200, 8, 2))
+		e.VAddPenalty(0)
+	}
+	e.SetPageTemplate(nil)
+	if w := e.ColumnWidth(); w != 95 {
+		t.Errorf("expected column width 95, got %g", w)
+	}
+
+	e.vConsumeMarkers()
+	page := e.makePage(false)
+	parts := page.(*vBox).Contents
+	if len(parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(parts))
+	}
+	if w := parts[0].Extent().Width; w != 410 {
+		t.Errorf("expected width 410, got %g", w)
+	}
+	for _, box := range parts[0].(*hBox).Contents {
+		if col, ok := box.(*vBox); ok && col.Width != 200 {
+			t.Errorf("expected column width 200, got %g", col.Width)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bufio"
+	"bytes"
+	"image"
+	gocolor "image/color"
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+	"io"
+	"maps"
+
+	"seehuhn.de/go/geom/matrix"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics"
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	pdfimage "seehuhn.de/go/pdf/graphics/image"
+)
+
+// Picture is a raster image, which can be placed on pages using
+// [Picture.Box].  The image is embedded in the PDF file only once, no matter
+// how often it is used.
+type Picture struct {
+	// Width and Height give the size of the image in pixels.
+	Width, Height int
+
+	xObj graphics.XObject
+}
+
+// ReadPicture reads a JPEG or PNG image.  JPEG data is embedded into the PDF
+// file unchanged.  Transparency in PNG images is preserved.
+func ReadPicture(r io.Reader) (*Picture, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if bytes.Equal(magic, []byte{0xFF, 0xD8}) {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		return jpegPicture(data)
+	}
+
+	img, _, err := image.Decode(br)
+	if err != nil {
+		return nil, err
+	}
+	return NewPicture(img)
+}
+
+// NewPicture converts a Go image into a Picture.  If the image has an alpha
+// channel, a soft mask is used to preserve transparency.
+func NewPicture(img image.Image) (*Picture, error) {
+	dict, err := pdfimage.PNG(img, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	return &Picture{Width: b.Dx(), Height: b.Dy(), xObj: dict}, nil
+}
+
+// jpegPicture returns a picture which embeds the given JPEG data.
+func jpegPicture(data []byte) (*Picture, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var cs color.Space
+	switch cfg.ColorModel {
+	case gocolor.GrayModel:
+		cs = color.SpaceDeviceGray
+	case gocolor.YCbCrModel:
+		cs = color.SpaceDeviceRGB
+	default:
+		// CMYK JPEG files differ in how the colour values are stored, so
+		// we re-encode these.
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return NewPicture(img)
+	}
+
+	dict := &pdfimage.Dict{
+		Width:            cfg.Width,
+		Height:           cfg.Height,
+		ColorSpace:       cs,
+		BitsPerComponent: 8,
+		Data:             jpegData(data),
+	}
+	return &Picture{Width: cfg.Width, Height: cfg.Height, xObj: dict}, nil
+}
+
+// jpegData is JPEG-encoded image data, which is copied into the PDF file
+// without re-encoding.
+type jpegData []byte
+
+// WriteStream implements [graphics.ImageData].
+func (d jpegData) WriteStream(rm *pdf.EmbedHelper, ref pdf.Reference, dict pdf.Dict) error {
+	dict = maps.Clone(dict)
+	dict["Filter"] = pdf.Name("DCTDecode")
+
+	w, err := rm.Out().OpenStream(ref, dict)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(d); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Pixels implements [graphics.ImageData].
+func (d jpegData) Pixels() ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(d))
+	if err != nil {
+		return nil, err
+	}
+	return (&pdfimage.DCTSource{Image: img}).Pixels()
+}
+
+// IsJPX implements [graphics.ImageData].
+func (d jpegData) IsJPX() bool { return false }
+
+// Box returns a box which shows the picture with the given width and
+// height.  If one of width and height is zero, it is chosen to preserve the
+// aspect ratio of the image.  If both are zero, the image is shown at a
+// resolution of 72 pixels per inch.
+//
+// The bottom edge of the picture is placed on the baseline.
+func (p *Picture) Box(width, height float64) Box {
+	switch {
+	case width == 0 && height == 0:
+		width, height = float64(p.Width), float64(p.Height)
+	case width == 0:
+		width = height * float64(p.Width) / float64(p.Height)
+	case height == 0:
+		height = width * float64(p.Height) / float64(p.Width)
+	}
+	return &pictureBox{
+		BoxExtent: BoxExtent{Width: width, Height: height},
+		pic:       p,
+	}
+}
+
+// BoxFit returns a box which shows the picture at the largest size which
+// fits into the given width and height, preserving the aspect ratio of the
+// image.
+func (p *Picture) BoxFit(maxWidth, maxHeight float64) Box {
+	if maxWidth*float64(p.Height) <= maxHeight*float64(p.Width) {
+		return p.Box(maxWidth, 0)
+	}
+	return p.Box(0, maxHeight)
+}
+
+type pictureBox struct {
+	BoxExtent
+	pic *Picture
+}
+
+// Draw implements the [Box] interface.
+func (obj *pictureBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	page.PushGraphicsState()
+	page.Transform(matrix.Matrix{
+		obj.Width, 0,
+		0, obj.Height + obj.Depth,
+		xPos, yPos - obj.Depth,
+	})
+	page.DrawXObject(obj.pic.xObj)
+	page.PopGraphicsState()
+}
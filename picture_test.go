@@ -0,0 +1,106 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"seehuhn.de/go/pdf/document"
+	pdfimage "seehuhn.de/go/pdf/graphics/image"
+)
+
+func TestPicture(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := range 20 {
+		for x := range 40 {
+			img.Set(x, y, color.NRGBA{R: uint8(6 * x), G: uint8(12 * y), B: 128, A: uint8(6 * x)})
+		}
+	}
+
+	pngData := &bytes.Buffer{}
+	err := png.Encode(pngData, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pngPic, err := ReadPicture(pngData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pngPic.Width != 40 || pngPic.Height != 20 {
+		t.Errorf("wrong size %dx%d", pngPic.Width, pngPic.Height)
+	}
+	if dict := pngPic.xObj.(*pdfimage.Dict); dict.SMask == nil {
+		t.Error("transparency was lost")
+	}
+
+	jpegBuf := &bytes.Buffer{}
+	err = jpeg.Encode(jpegBuf, img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jpegPic, err := ReadPicture(bytes.NewReader(jpegBuf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := jpegPic.xObj.(*pdfimage.Dict).Data.(jpegData); !ok || !bytes.Equal(data, jpegBuf.Bytes()) {
+		t.Error("JPEG data was not embedded unchanged")
+	}
+
+	if ext := pngPic.Box(0, 10).Extent(); ext.Width != 20 || ext.Height != 10 {
+		t.Errorf("wrong extent %v", ext)
+	}
+	if ext := pngPic.BoxFit(100, 10).Extent(); ext.Width != 20 || ext.Height != 10 {
+		t.Errorf("wrong extent %v", ext)
+	}
+	if ext := pngPic.BoxFit(10, 100).Extent(); ext.Width != 10 || ext.Height != 5 {
+		t.Errorf("wrong extent %v", ext)
+	}
+
+	fi := testFont(t)
+	doc, out := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   400,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+	e.VAddBox(jpegPic.BoxFit(e.TextWidth, 100))
+	e.HAddText(fi, "An icon ")
+	e.HAddBox(pngPic.Box(0, 8))
+	e.HAddText(fi, " in the text.")
+	e.EndParagraph()
+	err = e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"/DCTDecode", "/SMask"} {
+		if !bytes.Contains(out.Bytes(), []byte(key)) {
+			t.Errorf("%s not found in the PDF file", key)
+		}
+	}
+}
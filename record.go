@@ -17,6 +17,8 @@
 package layout
 
 import (
+	"seehuhn.de/go/geom/vec"
+
 	"seehuhn.de/go/pdf"
 	"seehuhn.de/go/pdf/graphics/content/builder"
 )
@@ -29,25 +31,59 @@ type recordPageLocation struct {
 
 func (r *recordPageLocation) Draw(page *builder.Builder, xPos, yPos float64) {
 	ext := r.Extent()
+	bi := newBoxInfo(page, xPos, yPos-ext.Depth, xPos+ext.Width, yPos+ext.Height)
+	r.e.addRecord(bi, r.cb)
+
+	r.Box.Draw(page, xPos, yPos)
+}
 
-	// TODO(voss): undo any coordinate transformations the user may have
-	// applied, to get "default user space units".
+func (r *recordPageLocation) children() []Box {
+	return []Box{r.Box}
+}
+
+// newBoxInfo returns a BoxInfo for the rectangle with corners (x0, y0) and
+// (x1, y1) in the current user space.  The page and page number are filled
+// in once the page is complete.
+func newBoxInfo(page *builder.Builder, x0, y0, x1, y1 float64) *BoxInfo {
+	// Map the corners of the box to default user space, to allow for any
+	// coordinate transformations in effect.
+	ctm := page.State.GState.CTM
+	quad := [4]vec.Vec2{
+		ctm.Apply(vec.Vec2{X: x0, Y: y0}),
+		ctm.Apply(vec.Vec2{X: x1, Y: y0}),
+		ctm.Apply(vec.Vec2{X: x1, Y: y1}),
+		ctm.Apply(vec.Vec2{X: x0, Y: y1}),
+	}
 	bbox := &pdf.Rectangle{
-		LLx: xPos,
-		LLy: yPos - ext.Depth,
-		URx: xPos + ext.Width,
-		URy: yPos + ext.Height,
+		LLx: quad[0].X,
+		LLy: quad[0].Y,
+		URx: quad[0].X,
+		URy: quad[0].Y,
 	}
-	bi := &BoxInfo{
-		BBox: bbox,
+	for _, p := range quad[1:] {
+		bbox.LLx = min(bbox.LLx, p.X)
+		bbox.LLy = min(bbox.LLy, p.Y)
+		bbox.URx = max(bbox.URx, p.X)
+		bbox.URy = max(bbox.URy, p.Y)
 	}
-	br := &boxRecord{
-		BoxInfo: bi,
-		cb:      r.cb,
+	return &BoxInfo{
+		BBox: bbox,
+		Quad: quad,
 	}
-	r.e.records = append(r.e.records, br)
+}
 
-	r.Box.Draw(page, xPos, yPos)
+// addRecord registers callbacks to be called with bi, once the current page
+// is complete.
+func (e *Engine) addRecord(bi *BoxInfo, cb []func(*BoxInfo)) {
+	e = e.root()
+	if e.repeating > 0 {
+		// no records for the repeated copies of table header rows
+		return
+	}
+	e.records = append(e.records, &boxRecord{
+		BoxInfo: bi,
+		cb:      cb,
+	})
 }
 
 type boxRecord struct {
@@ -0,0 +1,97 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/vec"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestRecordTransformed(t *testing.T) {
+	e := &Engine{}
+	box := &recordPageLocation{
+		Box: Rule(20, 10, 0),
+		e:   e,
+	}
+
+	b := builder.New(content.Page, nil, pdf.V1_7)
+	b.Transform(matrix.Translate(100, 200))
+	b.Transform(matrix.Scale(2, 2))
+	box.Draw(b, 5, 0)
+	b.Transform(matrix.RotateDeg(90))
+	box.Draw(b, 0, 0)
+	if b.Err != nil {
+		t.Fatal(b.Err)
+	}
+
+	if len(e.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(e.records))
+	}
+
+	bi := e.records[0].BoxInfo
+	want := &pdf.Rectangle{LLx: 110, LLy: 200, URx: 150, URy: 220}
+	if !bi.BBox.NearlyEqual(want, 1e-9) {
+		t.Errorf("expected %s, got %s", want, bi.BBox)
+	}
+	if !bi.isRect() {
+		t.Error("scaled box is not a rectangle")
+	}
+
+	bi = e.records[1].BoxInfo
+	want = &pdf.Rectangle{LLx: 80, LLy: 200, URx: 100, URy: 240}
+	if !bi.BBox.NearlyEqual(want, 1e-9) {
+		t.Errorf("expected %s, got %s", want, bi.BBox)
+	}
+	upperLeft := vec.Vec2{X: 80, Y: 200}
+	if d := bi.Quad[3].Sub(upperLeft); math.Hypot(d.X, d.Y) > 1e-9 {
+		t.Errorf("wrong corner %v", bi.Quad[3])
+	}
+}
+
+func TestIsRect(t *testing.T) {
+	// corners of a rectangle, with rounding errors
+	bi := &BoxInfo{
+		BBox: &pdf.Rectangle{LLx: 0, LLy: 0, URx: 20, URy: 10},
+		Quad: [4]vec.Vec2{
+			{X: 1e-12, Y: 0},
+			{X: 20, Y: -1e-12},
+			{X: 20 - 1e-12, Y: 10},
+			{X: 0, Y: 10},
+		},
+	}
+	if !bi.isRect() {
+		t.Error("rounding errors not ignored")
+	}
+
+	// a rhombus
+	bi.Quad = [4]vec.Vec2{
+		{X: 10, Y: 0},
+		{X: 20, Y: 5},
+		{X: 10, Y: 10},
+		{X: 0, Y: 5},
+	}
+	if bi.isRect() {
+		t.Error("rhombus reported as rectangle")
+	}
+}
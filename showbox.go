@@ -0,0 +1,208 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ShowBox writes a textual description of a box and its contents to w.
+// Each line describes one box, with nesting indicated by leading dots.
+// The description includes the extent of each box, the glue setting of
+// horizontal and vertical boxes, and the text of text boxes.  The output is
+// meant for debugging and for comparison in tests.
+func ShowBox(w io.Writer, box Box) error {
+	s := &boxShower{w: bufio.NewWriter(w)}
+	s.show(box, 0, 0)
+	return s.w.Flush()
+}
+
+// ShowLists writes a textual description of the current vertical mode list
+// and horizontal mode list to w, in the format used by [ShowBox].
+func (e *Engine) ShowLists(w io.Writer) error {
+	s := &boxShower{w: bufio.NewWriter(w)}
+	fmt.Fprintln(s.w, "### vertical list")
+	for _, box := range e.vList {
+		s.show(box, 0, 0)
+	}
+	fmt.Fprintln(s.w, "### horizontal list")
+	for _, item := range e.hList {
+		switch item := item.(type) {
+		case *hModeBox:
+			s.show(item.Box, 0, 0)
+		case *Glue:
+			s.show(item, 0, 0)
+		case *hModePenalty:
+			s.line(0, "penalty %g", item.Penalty)
+			if item.flagged {
+				fmt.Fprint(s.w, " flagged")
+			}
+			fmt.Fprintln(s.w)
+			if item.pre != nil {
+				s.line(1, "pre-break material")
+				fmt.Fprintln(s.w)
+				s.show(item.pre, 2, 0)
+			}
+		}
+	}
+	return s.w.Flush()
+}
+
+type boxShower struct {
+	w *bufio.Writer
+}
+
+// line starts a new output line at the given nesting level.
+// The line is not terminated.
+func (s *boxShower) line(level int, format string, args ...any) {
+	s.w.WriteString(strings.Repeat(". ", level))
+	fmt.Fprintf(s.w, format, args...)
+}
+
+// show describes box at the given nesting level.  If the box is glue
+// inside a box with glue set, adjust gives the change from the natural
+// size.
+func (s *boxShower) show(box Box, level int, adjust float64) {
+	switch obj := box.(type) {
+	case *hBox:
+		gs := newGlueSet(totalWidthAndGlue(obj.Contents), obj.Width)
+		s.line(level, "hbox %s%s\n", obj.BoxExtent, gs)
+		for _, child := range obj.Contents {
+			s.show(child, level+1, gs.adjust(child))
+		}
+		return
+	case *vBox:
+		gs := newGlueSet(totalHeightAndGlue(obj.Contents), obj.Height+obj.Depth)
+		s.line(level, "vbox %s%s\n", obj.BoxExtent, gs)
+		for _, child := range obj.Contents {
+			s.show(child, level+1, gs.adjust(child))
+		}
+		return
+	case *tableRow:
+		s.line(level, "table row %s\n", obj.BoxExtent)
+		for _, slot := range obj.cells {
+			s.line(level+1, "cell %d,%d\n", slot.row, slot.col)
+			s.show(slot.content, level+2, 0)
+		}
+		return
+	case *TextBox:
+		var text strings.Builder
+		for _, g := range obj.Glyphs.Seq {
+			text.WriteString(g.Text)
+		}
+		s.line(level, "text %q %s %s %gpt\n",
+			text.String(), obj.Extent(), obj.F.Font.PostScriptName(), obj.F.Size)
+	case *Glue:
+		name := "glue"
+		if obj.leader != nil {
+			name = leaderNames[obj.leaderKind]
+		}
+		s.line(level, "%s %s", name, obj.spec())
+		if adjust != 0 {
+			fmt.Fprintf(s.w, ", set to %g", obj.Length+adjust)
+		}
+		fmt.Fprintln(s.w)
+		if obj.leader != nil {
+			s.show(obj.leader, level+1, 0)
+		}
+	case *fixedLeaders:
+		g := obj.glue
+		s.line(level, "%s %g\n", leaderNames[g.leaderKind], obj.width)
+		s.show(g.leader, level+1, 0)
+	case Kern:
+		s.line(level, "kern %g\n", float64(obj))
+	case penalty:
+		s.line(level, "penalty %g\n", float64(obj))
+	case *ruleBox:
+		s.line(level, "rule %s\n", obj.BoxExtent)
+	case raiseBox:
+		s.line(level, "raise %g %s\n", obj.delta, obj.Extent())
+	case lapBox:
+		s.line(level, "%s %s\n", obj.name(), obj.Extent())
+	case smashBox:
+		s.line(level, "smash %s\n", obj.Extent())
+	case *transformBox:
+		s.line(level, "transform %g %s\n", obj.M[:4], obj.Extent())
+	case *clipBox:
+		s.line(level, "clip %s\n", obj.Extent())
+	case *Container:
+		s.line(level, "container %s", obj.BoxExtent)
+		if obj.Overflow() {
+			fmt.Fprint(s.w, " overflow")
+		}
+		fmt.Fprintln(s.w)
+	case *frameBox:
+		s.line(level, "frame %s\n", obj.Extent())
+	case *actualTextBox:
+		s.line(level, "actual text %q %s\n", obj.text, obj.Extent())
+	case *splitBox:
+		s.line(level, "splittable %s\n", obj.Extent())
+	case *taggedBox:
+		s.line(level, "tagged %s %s\n", obj.elem.Type, obj.Extent())
+	case *repeatedBox:
+		s.line(level, "repeated %s\n", obj.Extent())
+	case *artifactBox:
+		s.line(level, "artifact %s\n", obj.Extent())
+	case *recordPageLocation:
+		s.line(level, "record %s\n", obj.Extent())
+	case *tableFrame:
+		s.line(level, "table frame %s\n", obj.Extent())
+	default:
+		s.line(level, "%s %s\n", typeName(box), box.Extent())
+	}
+	for _, child := range boxChildren(box) {
+		s.show(child, level+1, 0)
+	}
+}
+
+var leaderNames = map[LeaderKind]string{
+	LeadersAligned:  "leaders",
+	LeadersCentered: "cleaders",
+	LeadersExpanded: "xleaders",
+}
+
+// String describes the glue setting in the format used by [ShowBox].
+func (gs glueSet) String() string {
+	if gs.ratio == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", glue set %g%s", gs.ratio, filName(gs.order))
+}
+
+// spec returns the natural length, stretch and shrink of the glue in the
+// format used by [ShowBox].
+func (g *Glue) spec() string {
+	res := fmt.Sprintf("%g", g.Length)
+	if g.Stretch.Val != 0 {
+		res += fmt.Sprintf(" plus %g%s", g.Stretch.Val, filName(g.Stretch.Order))
+	}
+	if g.Shrink.Val != 0 {
+		res += fmt.Sprintf(" minus %g%s", g.Shrink.Val, filName(g.Shrink.Order))
+	}
+	return res
+}
+
+// filName returns the TeX name for the given order of infinity.
+func filName(order int) string {
+	if order <= 0 {
+		return ""
+	}
+	return "fi" + strings.Repeat("l", order)
+}
@@ -0,0 +1,86 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShowBox(t *testing.T) {
+	box := VBox(
+		HBoxTo(100,
+			Rule(10, 8, 2),
+			Skip(10, 1, 1, 0, 0),
+			Raise(2, Rule(20, 4, 0)),
+		),
+		Kern(3),
+		HBoxTo(20,
+			Rule(10, 8, 2),
+			Skip(20, 0, 0, 10, 0),
+			Rule(10, 8, 2),
+		),
+	)
+	buf := &strings.Builder{}
+	err := ShowBox(buf, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `vbox 100x(21+2)
+. hbox 100x(8+2), glue set 60fil
+. . rule 10x(8+2)
+. . glue 10 plus 1fil, set to 70
+. . raise 2 20x(6-2)
+. . . rule 20x(4+0)
+. kern 3
+. hbox 20x(8+2), glue set -1
+. . rule 10x(8+2)
+. . glue 20 minus 10, set to 10
+. . rule 10x(8+2)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("wrong output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestShowLists(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{
+		TextWidth:    100,
+		BaseLineSkip: 12,
+	}
+	e.VAddBox(Text(fi, "Title"))
+	e.HAddText(fi, "one two")
+
+	buf := &strings.Builder{}
+	err := e.ShowLists(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"### vertical list\ntext \"Title\" ",
+		"### horizontal list\ntext \"one \" ",
+		"\nglue ",
+		"\ntext \"two\" ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in output:\n%s", want, got)
+		}
+	}
+}
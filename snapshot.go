@@ -0,0 +1,285 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Snapshot describes a laid out box and its contents.  Snapshots can be
+// stored using encoding/json, and compared using [DiffSnapshots], in order
+// to check the results of line and page breaking in tests, independently of
+// the PDF output.
+type Snapshot struct {
+	// Type names the kind of box, for example "hbox", "text" or "glue".
+	// Boxes implemented outside this package are named after their Go type.
+	Type string `json:"type"`
+
+	// X and Y give the position of the reference point of the box.
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Depth  float64 `json:"depth"`
+
+	// Text is the text shown by a text box.
+	Text string `json:"text,omitempty"`
+
+	// Value is the amount of a kern, the value of a penalty, or the natural
+	// length of glue.  For glue, Width gives the length after stretching or
+	// shrinking.  Infinite penalties, which cannot be represented in JSON,
+	// are stored as +10000 and -10000.
+	Value float64 `json:"value,omitempty"`
+
+	// GlueSet and GlueOrder describe how the glue in a horizontal or vertical
+	// box is set.  Positive values of GlueSet indicate stretching, negative
+	// values shrinking.
+	GlueSet   float64 `json:"glueSet,omitempty"`
+	GlueOrder int     `json:"glueOrder,omitempty"`
+
+	// Matrix, for transformed boxes, maps the coordinate system of the
+	// children to the coordinate system of the box.  The children are
+	// given relative to the reference point of the transformed contents.
+	Matrix []float64 `json:"matrix,omitempty"`
+
+	Children []*Snapshot `json:"children,omitempty"`
+}
+
+// NewSnapshot returns a snapshot of the given box, with the reference point
+// of the box at the origin.
+func NewSnapshot(box Box) *Snapshot {
+	return newSnapshot(box, 0, 0)
+}
+
+// VListSnapshot returns a snapshot of the current vertical mode list.  The
+// items of the list are stacked as in [VTop], with the baseline of the
+// first item at the origin.
+func (e *Engine) VListSnapshot() *Snapshot {
+	snap := newSnapshot(VTop(e.vList...), 0, 0)
+	snap.Type = "vertical list"
+	return snap
+}
+
+func newSnapshot(box Box, xPos, yPos float64) *Snapshot {
+	ext := box.Extent()
+	snap := &Snapshot{
+		Type:   typeName(box),
+		X:      xPos,
+		Y:      yPos,
+		Width:  ext.Width,
+		Height: ext.Height,
+		Depth:  ext.Depth,
+	}
+	child := func(box Box, xPos, yPos float64) {
+		snap.Children = append(snap.Children, newSnapshot(box, xPos, yPos))
+	}
+
+	switch obj := box.(type) {
+	case *hBox:
+		snap.Type = "hbox"
+		gs := newGlueSet(totalWidthAndGlue(obj.Contents), obj.Width)
+		snap.GlueSet, snap.GlueOrder = gs.ratio, gs.order
+		xx := horizontalLayout(xPos, obj.Width, obj.Contents...)
+		for i, box := range obj.Contents {
+			child(box, xx[i], yPos)
+			if _, isGlue := box.(*Glue); isGlue {
+				snap.Children[i].Width += gs.adjust(box)
+			}
+		}
+		return snap
+	case *vBox:
+		snap.Type = "vbox"
+		gs := newGlueSet(totalHeightAndGlue(obj.Contents), obj.Height+obj.Depth)
+		snap.GlueSet, snap.GlueOrder = gs.ratio, gs.order
+		yy := verticalLayout(yPos+obj.Height, obj.Height+obj.Depth, obj.Contents...)
+		for i, box := range obj.Contents {
+			child(box, xPos, yy[i])
+			if _, isGlue := box.(*Glue); isGlue {
+				snap.Children[i].Height += gs.adjust(box)
+			}
+		}
+		return snap
+	case *tableRow:
+		snap.Type = "table row"
+		for _, slot := range obj.cells {
+			x, y := obj.contentPos(slot, xPos, yPos)
+			child(slot.content, x, y)
+		}
+		return snap
+	case *transformBox:
+		snap.Type = "transform"
+		_, xMin, _, _, _ := obj.bounds()
+		M := obj.M
+		M[4] = xPos - xMin
+		M[5] = yPos
+		snap.Matrix = M[:]
+		child(obj.box, 0, 0)
+		return snap
+	case *TextBox:
+		snap.Type = "text"
+		var text strings.Builder
+		for _, g := range obj.Glyphs.Seq {
+			text.WriteString(g.Text)
+		}
+		snap.Text = text.String()
+	case *Glue:
+		snap.Type = "glue"
+		if obj.leader != nil {
+			snap.Type = leaderNames[obj.leaderKind]
+		}
+		snap.Value = obj.Length
+	case *fixedLeaders:
+		snap.Type = leaderNames[obj.glue.leaderKind]
+	case Kern:
+		snap.Type = "kern"
+		snap.Value = float64(obj)
+	case penalty:
+		snap.Type = "penalty"
+		snap.Value = max(min(float64(obj), snapshotInfPenalty), -snapshotInfPenalty)
+	case *ruleBox:
+		snap.Type = "rule"
+	case raiseBox:
+		snap.Type = "raise"
+		snap.Value = obj.delta
+		child(obj.box, xPos, yPos+obj.delta)
+		return snap
+	case lapBox:
+		snap.Type = obj.name()
+		child(obj.box, xPos-obj.shift*obj.box.Extent().Width, yPos)
+		return snap
+	case smashBox:
+		snap.Type = "smash"
+	case *clipBox:
+		snap.Type = "clip"
+	case *Container:
+		snap.Type = "container"
+	case *frameBox:
+		snap.Type = "frame"
+		border, padding := obj.sides()
+		child(obj.contents, xPos+border.Left+padding.Left, yPos)
+		return snap
+	case *actualTextBox:
+		snap.Type = "actual text"
+		snap.Text = obj.text
+	case *graphicsBox:
+		snap.Type = "graphics"
+	case *importedPageBox:
+		snap.Type = "imported page"
+	case *pictureBox:
+		snap.Type = "picture"
+	case *splitBox:
+		snap.Type = "splittable"
+	case *taggedBox:
+		snap.Type = "tagged"
+	case *repeatedBox:
+		snap.Type = "repeated"
+	case *artifactBox:
+		snap.Type = "artifact"
+	case *recordPageLocation:
+		snap.Type = "record"
+	case *tableFrame:
+		snap.Type = "table frame"
+	case *hRecordMark, *hRecordStart, *hRecordEnd:
+		snap.Type = "record mark"
+	case tabMark:
+		snap.Type = "tab"
+	case columnChange:
+		snap.Type = "column change"
+	case *pageTemplateChange:
+		snap.Type = "page template"
+	}
+
+	// Other boxes draw their contents at their own reference point.
+	for _, box := range boxChildren(box) {
+		child(box, xPos, yPos)
+	}
+	return snap
+}
+
+// snapshotInfPenalty is used in snapshots to represent infinite penalties.
+const snapshotInfPenalty = 10000
+
+// typeName returns a name for the type of a box, for use in debugging
+// output.
+func typeName(box Box) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", box), "*")
+	return strings.TrimPrefix(name, "layout.")
+}
+
+// DiffSnapshots compares two snapshots and returns a list of differences.
+// Positions and dimensions are considered equal if they differ by at most
+// tol.  If the snapshots agree, the result is empty.
+func DiffSnapshots(a, b *Snapshot, tol float64) []string {
+	var diffs []string
+	diffSnapshots(&diffs, "", a, b, tol)
+	return diffs
+}
+
+func diffSnapshots(diffs *[]string, path string, a, b *Snapshot, tol float64) {
+	report := func(field string, va, vb any) {
+		if path != "" {
+			field = path + "." + field
+		}
+		*diffs = append(*diffs, fmt.Sprintf("%s: %v != %v", field, va, vb))
+	}
+	if a.Type != b.Type {
+		report("type", a.Type, b.Type)
+		return
+	}
+	if a.Text != b.Text {
+		report("text", fmt.Sprintf("%q", a.Text), fmt.Sprintf("%q", b.Text))
+	}
+	for _, f := range []struct {
+		name   string
+		va, vb float64
+	}{
+		{"x", a.X, b.X},
+		{"y", a.Y, b.Y},
+		{"width", a.Width, b.Width},
+		{"height", a.Height, b.Height},
+		{"depth", a.Depth, b.Depth},
+		{"value", a.Value, b.Value},
+		{"glueSet", a.GlueSet, b.GlueSet},
+	} {
+		if f.va != f.vb && !(math.Abs(f.va-f.vb) <= tol) {
+			report(f.name, f.va, f.vb)
+		}
+	}
+	if a.GlueOrder != b.GlueOrder {
+		report("glueOrder", a.GlueOrder, b.GlueOrder)
+	}
+	if len(a.Matrix) != len(b.Matrix) {
+		report("matrix", a.Matrix, b.Matrix)
+	} else {
+		for i := range a.Matrix {
+			if math.Abs(a.Matrix[i]-b.Matrix[i]) > tol {
+				report("matrix", a.Matrix, b.Matrix)
+				break
+			}
+		}
+	}
+	if len(a.Children) != len(b.Children) {
+		report("children", len(a.Children), len(b.Children))
+		return
+	}
+	for i := range a.Children {
+		diffSnapshots(diffs, fmt.Sprintf("%s[%d]", path, i), a.Children[i], b.Children[i], tol)
+	}
+}
@@ -0,0 +1,210 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestSnapshotPositions(t *testing.T) {
+	box := HBoxTo(100,
+		Rule(10, 8, 2),
+		Skip(10, 1, 1, 0, 0),
+		Raise(2, Rule(20, 4, 0)),
+	)
+	snap := NewSnapshot(box)
+	if snap.Type != "hbox" || snap.GlueSet != 60 || snap.GlueOrder != 1 {
+		t.Errorf("wrong glue setting %q %g %d", snap.Type, snap.GlueSet, snap.GlueOrder)
+	}
+	glue := snap.Children[1]
+	if glue.X != 10 || glue.Width != 70 || glue.Value != 10 {
+		t.Errorf("wrong glue %v", glue)
+	}
+	raised := snap.Children[2]
+	if raised.X != 80 || raised.Children[0].Y != 2 {
+		t.Errorf("wrong position (%g, %g)", raised.X, raised.Children[0].Y)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{
+		TextWidth:    100,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+	e.HAddText(fi, "Some words, which are broken into a few lines.")
+	e.EndParagraph()
+	e.VAddPenalty(PenaltyForceBreak)
+	e.VAddBox(Rule(10, 10, 0))
+	e.VAddPenalty(PenaltyPreventBreak)
+	e.VAddBox(Rule(10, 10, 0))
+	snap := e.VListSnapshot()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored *Snapshot
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := DiffSnapshots(snap, stored, 0); len(diffs) > 0 {
+		t.Errorf("round trip changed the snapshot: %v", diffs)
+	}
+	var penalties []float64
+	for _, child := range stored.Children {
+		if child.Type == "penalty" {
+			penalties = append(penalties, child.Value)
+		}
+	}
+	if n := len(penalties); n < 2 || penalties[n-2] != -10000 || penalties[n-1] != 10000 {
+		t.Errorf("wrong penalties %v", penalties)
+	}
+
+	inf := &Snapshot{Type: "penalty", Value: math.Inf(1)}
+	if diffs := DiffSnapshots(inf, inf, 0); len(diffs) > 0 {
+		t.Errorf("unexpected differences: %v", diffs)
+	}
+
+	stored.Children[2].Y += 1e-4
+	if diffs := DiffSnapshots(snap, stored, 1e-3); len(diffs) > 0 {
+		t.Errorf("unexpected differences: %v", diffs)
+	}
+	stored.Children[2].Y += 0.5
+	stored.Children[0].Children[0].Text = "other"
+	diffs := DiffSnapshots(snap, stored, 1e-3)
+	if len(diffs) != 2 {
+		t.Errorf("expected 2 differences, got %v", diffs)
+	}
+}
+
+func TestPageSnapshot(t *testing.T) {
+	doc, _ := newTestDoc(t)
+	var pages []*Snapshot
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    100,
+		TextHeight:   50,
+		BaseLineSkip: 12,
+		PageSnapshotFunc: func(pageNo int, snap *Snapshot) {
+			if pageNo != len(pages)+1 {
+				t.Errorf("wrong page number %d", pageNo)
+			}
+			pages = append(pages, snap)
+		},
+	}
+	for range 6 {
+		e.VAddBox(Rule(100, 10, 0))
+		e.VAddPenalty(0)
+	}
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	first := pages[0]
+	if first.X != 72 || first.Y != 72 || first.Height != 50 {
+		t.Errorf("wrong page box %v", first)
+	}
+}
+
+func TestSnapshotTable(t *testing.T) {
+	tall := &posBox{BoxExtent: BoxExtent{Width: 10, Height: 20, Depth: 5}}
+	small := &posBox{BoxExtent: BoxExtent{Width: 10, Height: 4, Depth: 1}}
+	cell := BoxCell(small)
+	cell.VAlign = VAlignBottom
+
+	e := &Engine{TextWidth: 200, BaseLineSkip: 12}
+	table := &Table{Padding: 3, Rules: RulesAll}
+	table.AddRow(BoxCell(tall), cell)
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	box := VTop(e.vList...)
+	snap := NewSnapshot(box)
+	b := builder.New(content.Page, nil, pdf.V1_7)
+	box.Draw(b, 0, 0)
+	if b.Err != nil {
+		t.Fatal(b.Err)
+	}
+
+	// The snapshot must show the cell contents where they are drawn.
+	var found []*Snapshot
+	var walk func(s *Snapshot)
+	walk = func(s *Snapshot) {
+		if s.Type == "posBox" {
+			found = append(found, s)
+		}
+		for _, c := range s.Children {
+			walk(c)
+		}
+	}
+	walk(snap)
+	if len(found) != 2 {
+		t.Fatalf("found %d cells, expected 2", len(found))
+	}
+	for i, obj := range []*posBox{tall, small} {
+		if math.Abs(found[i].X-obj.x) > 1e-6 || math.Abs(found[i].Y-obj.y) > 1e-6 {
+			t.Errorf("cell %d: snapshot at (%g, %g), drawn at (%g, %g)",
+				i, found[i].X, found[i].Y, obj.x, obj.y)
+		}
+	}
+	if found[1].Y >= found[0].Y {
+		t.Error("bottom aligned cell not moved down")
+	}
+}
+
+func TestSnapshotTransform(t *testing.T) {
+	snap := NewSnapshot(HBox(Kern(5), Rotate(90, Rule(10, 4, 0))))
+	tr := snap.Children[1]
+	if tr.Type != "transform" || len(tr.Children) != 1 {
+		t.Fatalf("wrong snapshot %v", tr)
+	}
+	want := []float64{0, 1, -1, 0, 9, 0}
+	if len(tr.Matrix) != 6 {
+		t.Fatalf("wrong matrix %v", tr.Matrix)
+	}
+	for i := range want {
+		if math.Abs(tr.Matrix[i]-want[i]) > 1e-6 {
+			t.Errorf("wrong matrix %v", tr.Matrix)
+			break
+		}
+	}
+	rule := tr.Children[0]
+	if rule.Type != "rule" || rule.X != 0 || rule.Y != 0 {
+		t.Errorf("wrong contents %v", rule)
+	}
+}
@@ -0,0 +1,205 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// VSplit splits a vertical box at the best break point for the given height.
+// The first return value is a box of the given height, containing the
+// material above the break.  The second return value is a VTop box with
+// the remaining material, or nil if all material fits.
+//
+// If box is not a vertical box, or if not even the first item of the box
+// fits into the given height, the first return value is nil and the second
+// return value is box.
+func (e *Engine) VSplit(box Box, height float64) (Box, Box) {
+	vbox, ok := box.(*vBox)
+	if !ok || len(vbox.Contents) == 0 {
+		return nil, box
+	}
+
+	// The part above the break does not need to fill the height, so
+	// infinitely stretchable glue is added at the bottom.  This keeps the
+	// badness of short parts finite, so that penalties are taken into
+	// account.
+	cand := vBreakCandidates(vbox.Contents, height, 0, Skip(0, 1, 1, 0, 0))
+	best := vBestCandidate(cand)
+	if best.overfull {
+		return nil, box
+	}
+
+	top := VBoxTo(height, vbox.Contents[:best.pos]...)
+	rest := vDropDiscardible(vbox.Contents[best.pos:])
+	if len(rest) == 0 {
+		return top, nil
+	}
+	return top, VTop(rest...)
+}
+
+// SplitFrame decorates the parts of a splittable box.  The function is
+// called with the material for one part, and must return the box to place on
+// the page.  The flags first and last indicate whether the part is at the
+// start or the end of the original box, so that frames can be left open and
+// continuation markers can be added where the box is split.
+type SplitFrame func(part Box, first, last bool) Box
+
+// VAddSplittable adds a vertical box to the vertical mode list, which can be
+// split across pages if needed.  The box must have been created by one of
+// [VBox], [VTop], [VBoxTo] or [Engine.MakeVTop].  If frame is not nil, it is
+// used to decorate each part of the box.
+//
+// Splittable boxes are only split when page breaks are chosen page by page,
+// see [Engine.AppendPages].  Inside a group of material which is kept
+// together, see [Engine.VBeginKeep] and [Engine.VKeepWithNext], the box is
+// not split.
+func (e *Engine) VAddSplittable(box Box, frame SplitFrame) {
+	e.VAddBox(newSplitBox(box, frame, true))
+}
+
+// splitBox is a box in the vertical list, which makePage may split
+// across pages.
+type splitBox struct {
+	Box // the decorated contents
+
+	contents Box
+	frame    SplitFrame
+	first    bool
+	keep     bool // the box is inside a group kept on one page
+
+	e    *Engine
+	elem *StructElem // structure element for tagged PDF, or nil
+
+	// cb holds the callbacks registered using [Engine.VRecordNextBox].
+	// They are called for the first part of the box only.
+	cb []func(*BoxInfo)
+}
+
+func newSplitBox(contents Box, frame SplitFrame, first bool) *splitBox {
+	res := &splitBox{
+		Box:      contents,
+		contents: contents,
+		frame:    frame,
+		first:    first,
+	}
+	if frame != nil {
+		res.Box = frame(contents, first, true)
+	}
+	return res
+}
+
+// split divides the box into a part of at most the given total height and
+// a splittable box with the remaining material.  If the box cannot be split,
+// nil is returned.
+func (obj *splitBox) split(e *Engine, avail float64) (Box, *splitBox) {
+	var overhead float64
+	if obj.frame != nil {
+		ext := obj.frame(VBox(), obj.first, false).Extent()
+		overhead = ext.Height + ext.Depth
+	}
+
+	top, rest := e.VSplit(obj.contents, avail-overhead)
+	if top == nil || rest == nil {
+		return nil, nil
+	}
+	if obj.frame != nil {
+		top = obj.frame(top, obj.first, false)
+	}
+	if obj.elem != nil {
+		top = &taggedBox{Box: top, e: e, elem: obj.elem}
+	}
+	if len(obj.cb) > 0 {
+		top = &recordPageLocation{Box: top, e: e, cb: obj.cb}
+	}
+	next := newSplitBox(rest, obj.frame, false)
+	next.keep = obj.keep
+	next.e, next.elem = e, obj.elem
+	if e.Tagged {
+		e.markArtifacts(top)
+		e.markArtifacts(next.Box)
+	}
+	return top, next
+}
+
+func (obj *splitBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	if len(obj.cb) > 0 {
+		ext := obj.Extent()
+		bi := newBoxInfo(page, xPos, yPos-ext.Depth, xPos+ext.Width, yPos+ext.Height)
+		obj.e.addRecord(bi, obj.cb)
+	}
+	if obj.elem == nil {
+		obj.Box.Draw(page, xPos, yPos)
+		return
+	}
+	obj.e.beginMarked(page, obj.elem)
+	obj.Box.Draw(page, xPos, yPos)
+	obj.e.endMarked(page)
+}
+
+func (obj *splitBox) children() []Box {
+	return []Box{obj.Box}
+}
+
+func isSplitBox(box Box) bool {
+	_, ok := box.(*splitBox)
+	return ok
+}
+
+// vFindSplit finds a splittable box which extends beyond the bottom of a
+// column of the given height.  The function returns the index of the box in
+// vList and the space available for the box, or -1 if there is no such box.
+func vFindSplit(vList []Box, height, topSkip float64) (int, float64) {
+	length := topSkip
+	prevDepth := 0.0
+	for i, box := range vList {
+		if _, isPenalty := box.(penalty); isPenalty {
+			continue
+		}
+
+		ext := box.Extent()
+		if _, ok := box.(*splitBox); ok && length+prevDepth+ext.Height+ext.Depth > height {
+			return i, height - length - prevDepth
+		}
+
+		length += ext.Height + prevDepth
+		prevDepth = ext.Depth
+		if length > height {
+			break
+		}
+	}
+	return -1, 0
+}
+
+// vSplitAt returns a copy of vList where the splittable box at position k is
+// split into a part of at most the given height, a break point, and the
+// remaining material.  If the box cannot be split, nil is returned.  Inside
+// a group of material kept together, breaking at the split is prevented.
+func (e *Engine) vSplitAt(vList []Box, k int, avail float64) []Box {
+	box := vList[k].(*splitBox)
+	top, rest := box.split(e, avail)
+	if top == nil {
+		return nil
+	}
+	p := penalty(0)
+	if box.keep {
+		p = penalty(PenaltyPreventBreak)
+	}
+	return slices.Concat(vList[:k], []Box{top, p, rest}, vList[k+1:])
+}
@@ -0,0 +1,138 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+)
+
+func TestVSplit(t *testing.T) {
+	box := VTop(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0),
+	)
+
+	e := &Engine{}
+	top, rest := e.VSplit(box, 25)
+	if top == nil || rest == nil {
+		t.Fatal("box was not split")
+	}
+	if h := top.Extent().Height; h != 25 {
+		t.Errorf("expected height 25, got %g", h)
+	}
+	if n := len(top.(*vBox).Contents); n != 3 {
+		t.Errorf("expected 3 items in first part, got %d", n)
+	}
+	if n := len(rest.(*vBox).Contents); n != 1 {
+		t.Errorf("expected 1 item in second part, got %d", n)
+	}
+
+	top, rest = e.VSplit(box, 5)
+	if top != nil || rest != box {
+		t.Error("expected no split")
+	}
+
+	// penalties are taken into account
+	box = VTop(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(5000),
+		Rule(10, 10, 0),
+	)
+	top, _ = e.VSplit(box, 25)
+	if n := len(top.(*vBox).Contents); n != 1 {
+		t.Errorf("expected 1 item in first part, got %d", n)
+	}
+}
+
+func TestSplittable(t *testing.T) {
+	type call struct{ first, last bool }
+	var calls []call
+	frame := func(part Box, first, last bool) Box {
+		calls = append(calls, call{first, last})
+		return VBox(Kern(1), part, Kern(1))
+	}
+
+	e := &Engine{
+		TextHeight: 30,
+	}
+	e.VAddBox(Rule(10, 10, 0))
+	e.VAddSplittable(VTop(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0),
+	), frame)
+
+	page := e.makePage(false)
+	if n := len(page.(*vBox).Contents); n != 2 {
+		t.Errorf("expected 2 items on the page, got %d", n)
+	}
+	if len(e.vList) != 1 {
+		t.Fatalf("expected 1 item left over, got %d", len(e.vList))
+	}
+	rest, ok := e.vList[0].(*splitBox)
+	if !ok || rest.first {
+		t.Errorf("expected continuation of the splittable box, got %T", e.vList[0])
+	}
+
+	last := calls[len(calls)-1]
+	if last.first || !last.last {
+		t.Errorf("expected final frame for the continuation, got %v", last)
+	}
+}
+
+func TestFindSplitDepth(t *testing.T) {
+	// Most of a VTop box is below the baseline.
+	box := newSplitBox(VTop(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0),
+	), nil, true)
+	vList := []Box{Rule(10, 10, 0), box}
+
+	k, avail := vFindSplit(vList, 30, 0)
+	if k != 1 || avail != 20 {
+		t.Errorf("got %d, %g, want 1, 20", k, avail)
+	}
+}
+
+func TestSplittableKeep(t *testing.T) {
+	e := &Engine{
+		TextHeight: 30,
+		BottomGlue: Skip(0, 1, 1, 0, 0),
+	}
+	e.VAddBox(Rule(10, 10, 0))
+	e.VAddPenalty(0)
+	e.VBeginKeep()
+	e.VAddBox(Rule(10, 10, 0))
+	e.VAddSplittable(VBox(
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0), penalty(0),
+		Rule(10, 10, 0),
+	), nil)
+	e.VEndKeep()
+
+	// The page breaks before the group, instead of inside the box.
+	e.makePage(false)
+	if len(e.vList) != 2 {
+		t.Fatalf("expected 2 items left over, got %d", len(e.vList))
+	}
+	if rest, ok := e.vList[1].(*splitBox); !ok || !rest.first {
+		t.Errorf("expected the splittable box in one piece, got %T", e.vList[1])
+	}
+}
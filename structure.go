@@ -0,0 +1,481 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"maps"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/numtree"
+	"seehuhn.de/go/pdf/optional"
+	"seehuhn.de/go/pdf/page"
+	"seehuhn.de/go/pdf/property"
+)
+
+// StructElem is an element of the logical structure of a tagged PDF
+// document.
+type StructElem struct {
+	// Type is the structure type, for example "P", "H1", "L", "LI" or
+	// "Figure".
+	Type string
+
+	// Alt (optional) is an alternate description of the element, for
+	// example for figures.
+	Alt string
+
+	parent *StructElem
+	kids   []any // *StructElem or *mcRef
+	ref    pdf.Reference
+}
+
+// mcRef refers to a marked-content sequence on a page.
+type mcRef struct {
+	page pdf.Reference
+	mcid int
+}
+
+// groupingTypes lists the standard structure types which group other
+// structure elements.  Paragraphs inside these elements are tagged as "P".
+var groupingTypes = map[string]bool{
+	"Document":   true,
+	"Part":       true,
+	"Art":        true,
+	"Sect":       true,
+	"Div":        true,
+	"BlockQuote": true,
+	"TOC":        true,
+	"TOCI":       true,
+	"Index":      true,
+	"NonStruct":  true,
+	"L":          true,
+	"LI":         true,
+	"Table":      true,
+	"THead":      true,
+	"TBody":      true,
+	"TFoot":      true,
+	"TR":         true,
+}
+
+// BeginStruct opens a new structure element of the given type, as a child
+// of the current element.  Material added until the matching call to
+// [Engine.EndStruct] belongs to the new element.  Structure elements are
+// only used if Tagged is set.
+//
+// Paragraphs finished by [Engine.EndParagraph] inside a grouping element,
+// like "Sect", "L", "LI" or "TR", are tagged as "P".  Inside other elements,
+// like "H1" or "LBody", the lines of the paragraph belong to the element
+// directly.
+func (e *Engine) BeginStruct(typ string) *StructElem {
+	parent := e.structCurrent()
+	elem := &StructElem{
+		Type:   typ,
+		parent: parent,
+	}
+	parent.kids = append(parent.kids, elem)
+	e.structCur = elem
+	return elem
+}
+
+// EndStruct closes the structure element opened by the most recent unmatched
+// call to [Engine.BeginStruct].
+func (e *Engine) EndStruct() {
+	if e.structCur != nil && e.structCur.parent != nil {
+		e.structCur = e.structCur.parent
+	}
+}
+
+// VAddFigure adds a box to the vertical mode list, tagged as a figure with
+// the given alternate description.  Pictures and graphics added using
+// [Engine.VAddBox] are tagged as figures, too, but without a description.
+func (e *Engine) VAddFigure(box Box, alt string) {
+	fig := e.BeginStruct("Figure")
+	fig.Alt = alt
+	e.VAddBox(box)
+	e.EndStruct()
+}
+
+// structCurrent returns the current structure element, creating the
+// document element if needed.
+func (e *Engine) structCurrent() *StructElem {
+	if e.structCur == nil {
+		e.structRoot = &StructElem{Type: "Document"}
+		e.structCur = e.structRoot
+	}
+	return e.structCur
+}
+
+// tagBox marks b as content of the current structure element.  Pictures
+// and graphics get a "Figure" element of their own.
+//
+// Marked-content sequences for structure elements cannot be nested.  If b
+// already contains tagged content, for example lines from
+// [Engine.MakeVTop] of another tagged engine, b is not marked again.
+// Instead, the tagged content is moved into the structure tree of e.
+func (e *Engine) tagBox(b Box) Box {
+	e.markArtifacts(b)
+	elem := e.structCurrent()
+	if isFigure(b) && elem.Type != "Figure" {
+		elem = e.BeginStruct("Figure")
+		e.EndStruct()
+	}
+	if e.adoptTagged(b, elem) {
+		return b
+	}
+	if sb, ok := b.(*splitBox); ok {
+		sb.e, sb.elem = e, elem
+		return sb
+	}
+	return &taggedBox{Box: b, e: e, elem: elem}
+}
+
+// isFigure reports whether b is an image or a graphic, which is tagged as a
+// "Figure" element.
+func isFigure(b Box) bool {
+	switch b.(type) {
+	case *pictureBox, *graphicsBox, *importedPageBox:
+		return true
+	}
+	return false
+}
+
+// adoptTagged moves the tagged content inside b to e, placing structure
+// elements from other engines below parent.  The function reports whether
+// b contains any tagged content.
+func (e *Engine) adoptTagged(b Box, parent *StructElem) bool {
+	found := false
+	var walk func(box Box)
+	walk = func(box Box) {
+		switch obj := box.(type) {
+		case *taggedBox:
+			obj.e, obj.elem = e, e.adoptElem(obj.elem, parent)
+			found = true
+			return
+		case *splitBox:
+			if obj.elem != nil {
+				obj.e, obj.elem = e, e.adoptElem(obj.elem, parent)
+				found = true
+				return
+			}
+		}
+		for _, child := range boxChildren(box) {
+			walk(child)
+		}
+	}
+	walk(b)
+	return found
+}
+
+// adoptElem moves the structure tree containing elem into the structure
+// tree of e, below parent, and returns the element to use in place of elem.
+// The root element of a foreign tree is replaced by parent.
+func (e *Engine) adoptElem(elem, parent *StructElem) *StructElem {
+	root := elem
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root == e.structRoot {
+		return elem
+	}
+
+	for _, kid := range root.kids {
+		if kid, ok := kid.(*StructElem); ok {
+			kid.parent = parent
+			parent.kids = append(parent.kids, kid)
+		}
+	}
+	root.kids = nil
+	if elem == root {
+		return parent
+	}
+	return elem
+}
+
+// markArtifacts arranges for the decoration inside b, like leaders, frames,
+// and the rules and backgrounds of tables, to be marked as artifacts.
+func (e *Engine) markArtifacts(b Box) {
+	switch obj := b.(type) {
+	case *Glue:
+		if obj.leader != nil {
+			obj.e = e
+		}
+	case *fixedLeaders:
+		obj.glue.e = e
+	case *frameBox:
+		obj.e = e
+	case *tableRow:
+		obj.e = e
+	case *tableFrame:
+		obj.e = e
+	}
+	for _, child := range boxChildren(b) {
+		e.markArtifacts(child)
+	}
+}
+
+// taggedBox is a box whose contents belong to a structure element.
+type taggedBox struct {
+	Box
+	e    *Engine
+	elem *StructElem
+}
+
+func (obj *taggedBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	if obj.e.root().repeating > 0 {
+		obj.Box.Draw(page, xPos, yPos)
+		return
+	}
+	obj.e.beginMarked(page, obj.elem)
+	obj.Box.Draw(page, xPos, yPos)
+	obj.e.endMarked(page)
+}
+
+func (obj *taggedBox) children() []Box {
+	return []Box{obj.Box}
+}
+
+// repeatedBox draws material which is repeated on several pages, like the
+// header rows of a table.  In tagged PDF, the repeated copies are marked as
+// artifacts, and the tagged content inside is not marked again.
+type repeatedBox struct {
+	Box
+	e *Engine
+}
+
+func (obj *repeatedBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	root := obj.e.root()
+	root.artifact(page, "Pagination", func() error {
+		root.repeating++
+		obj.Box.Draw(page, xPos, yPos)
+		root.repeating--
+		return nil
+	})
+}
+
+func (obj *repeatedBox) children() []Box {
+	return []Box{obj.Box}
+}
+
+// beginMarked starts a marked-content sequence for the given structure
+// element.
+func (e *Engine) beginMarked(page *builder.Builder, elem *StructElem) {
+	mcid := len(e.pageMCIDs)
+	e.pageMCIDs = append(e.pageMCIDs, elem)
+	ref := &mcRef{mcid: mcid}
+	elem.kids = append(elem.kids, ref)
+	e.pageMCRefs = append(e.pageMCRefs, ref)
+
+	page.MarkedContentStart(&graphics.MarkedContent{
+		Tag:        pdf.Name(elem.Type),
+		Properties: mcProperties{"MCID": pdf.Integer(mcid)},
+		Inline:     true,
+	})
+	e.marked = elem
+}
+
+// endMarked ends the marked-content sequence started by beginMarked.
+func (e *Engine) endMarked(page *builder.Builder) {
+	page.MarkedContentEnd()
+	e.marked = nil
+}
+
+// artifact calls draw.  If Tagged is set, everything drawn is marked as an
+// artifact of the given type, for example "Pagination" for running headers
+// and page numbers, or "Layout" for rules and backgrounds.  Since artifacts
+// cannot be nested inside tagged content, an open marked-content sequence
+// for a structure element is closed first, and continued afterwards.
+func (e *Engine) artifact(page *builder.Builder, typ pdf.Name, draw func() error) error {
+	if !e.Tagged || e.inArtifact > 0 {
+		return draw()
+	}
+
+	elem := e.marked
+	if elem != nil {
+		e.endMarked(page)
+	}
+	e.inArtifact++
+	page.MarkedContentStart(&graphics.MarkedContent{
+		Tag:        "Artifact",
+		Properties: mcProperties{"Type": typ},
+		Inline:     true,
+	})
+	err := draw()
+	page.MarkedContentEnd()
+	e.inArtifact--
+	if elem != nil {
+		e.beginMarked(page, elem)
+	}
+	return err
+}
+
+// decorate calls draw, to draw decoration like rules and backgrounds.  If e
+// is not nil and its document is tagged, the decoration is marked as a
+// layout artifact.
+func decorate(e *Engine, page *builder.Builder, draw func()) {
+	if e == nil {
+		draw()
+		return
+	}
+	e.root().artifact(page, "Layout", func() error {
+		draw()
+		return nil
+	})
+}
+
+// artifactBox is a box which only contains decoration, like a column rule.
+type artifactBox struct {
+	Box
+	e *Engine
+}
+
+func (obj *artifactBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	decorate(obj.e, page, func() {
+		obj.Box.Draw(page, xPos, yPos)
+	})
+}
+
+func (obj *artifactBox) children() []Box {
+	return []Box{obj.Box}
+}
+
+// structFinishPage assigns the marked content on the current page to the
+// page p, and registers the page in the parent tree.
+func (e *Engine) structFinishPage(w *pdf.Writer, p *page.Page, pageRef pdf.Reference) {
+	if len(e.pageMCIDs) == 0 {
+		return
+	}
+
+	for _, ref := range e.pageMCRefs {
+		ref.page = pageRef
+	}
+	parents := make(pdf.Array, len(e.pageMCIDs))
+	for mcid, elem := range e.pageMCIDs {
+		parents[mcid] = elem.getRef(w)
+	}
+	p.StructParents = optional.NewUInt(uint(len(e.structParents)))
+	e.structParents = append(e.structParents, parents)
+
+	e.pageMCIDs = e.pageMCIDs[:0]
+	e.pageMCRefs = e.pageMCRefs[:0]
+}
+
+func (elem *StructElem) getRef(w *pdf.Writer) pdf.Reference {
+	if elem.ref == 0 {
+		elem.ref = w.Alloc()
+	}
+	return elem.ref
+}
+
+// writeStructTree writes the structure tree and marks the document as
+// tagged.
+func (e *Engine) writeStructTree(rm *pdf.ResourceManager) error {
+	w := rm.Out
+	rootRef := w.Alloc()
+
+	err := e.structRoot.write(w, rootRef)
+	if err != nil {
+		return err
+	}
+
+	parentTree, err := numtree.Write(w, func(yield func(pdf.Integer, pdf.Object) bool) {
+		for key, parents := range e.structParents {
+			if !yield(pdf.Integer(key), parents) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	root := pdf.Dict{
+		"Type":              pdf.Name("StructTreeRoot"),
+		"K":                 e.structRoot.getRef(w),
+		"ParentTreeNextKey": pdf.Integer(len(e.structParents)),
+	}
+	if parentTree != 0 {
+		root["ParentTree"] = parentTree
+	}
+	err = w.Put(rootRef, root)
+	if err != nil {
+		return err
+	}
+
+	catalog := w.GetMeta().Catalog
+	catalog.StructTreeRoot = rootRef
+	catalog.MarkInfo = pdf.Dict{"Marked": pdf.Boolean(true)}
+	return nil
+}
+
+// write writes the structure element and all its descendants.
+func (elem *StructElem) write(w *pdf.Writer, parent pdf.Reference) error {
+	ref := elem.getRef(w)
+
+	var kids pdf.Array
+	for _, kid := range elem.kids {
+		switch kid := kid.(type) {
+		case *StructElem:
+			err := kid.write(w, ref)
+			if err != nil {
+				return err
+			}
+			kids = append(kids, kid.ref)
+		case *mcRef:
+			if kid.page == 0 {
+				continue
+			}
+			kids = append(kids, pdf.Dict{
+				"Type": pdf.Name("MCR"),
+				"Pg":   kid.page,
+				"MCID": pdf.Integer(kid.mcid),
+			})
+		}
+	}
+
+	dict := pdf.Dict{
+		"Type": pdf.Name("StructElem"),
+		"S":    pdf.Name(elem.Type),
+		"P":    parent,
+	}
+	if len(kids) > 0 {
+		dict["K"] = kids
+	}
+	if elem.Alt != "" {
+		dict["Alt"] = pdf.TextString(elem.Alt)
+	}
+	return w.Put(ref, dict)
+}
+
+// mcProperties is a property list for marked content, which is embedded
+// directly in the content stream.
+type mcProperties pdf.Dict
+
+var _ property.List = mcProperties(nil)
+
+func (p mcProperties) AsDirectDict() pdf.Dict {
+	return pdf.Dict(p)
+}
+
+func (p mcProperties) Equal(other property.List) bool {
+	q, ok := other.(mcProperties)
+	return ok && maps.Equal(p, q)
+}
+
+func (p mcProperties) Embed(rm *pdf.EmbedHelper) (pdf.Native, error) {
+	return pdf.Dict(p), nil
+}
@@ -0,0 +1,255 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/page"
+)
+
+func TestStructure(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	var pages []*page.Page
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   200,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		Tagged:       true,
+		AfterPageFunc: func(_ int, b *builder.Builder) error {
+			b.Rectangle(0, 0, 10, 10)
+			b.Fill()
+			return nil
+		},
+		AfterCloseFunc: func(p *page.Page) error {
+			pages = append(pages, p)
+			return nil
+		},
+	}
+
+	e.BeginStruct("H1")
+	e.HAddText(fi, "Introduction")
+	e.EndParagraph()
+	e.EndStruct()
+	e.HAddText(fi, "Some text.")
+	e.EndParagraph()
+	e.VAddFigure(Rule(10, 10, 0), "A black square")
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := doc.Out.GetMeta().Catalog
+	if catalog.StructTreeRoot == nil || catalog.MarkInfo == nil {
+		t.Error("structure tree not written")
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key, ok := pages[0].StructParents.Get(); !ok || key != 0 {
+		t.Error("missing StructParents entry")
+	}
+	if len(e.structParents) != 1 || len(e.structParents[0]) != 3 {
+		t.Fatalf("wrong parent tree %v", e.structParents)
+	}
+
+	var types []string
+	for _, kid := range e.structRoot.kids {
+		elem := kid.(*StructElem)
+		types = append(types, elem.Type)
+		if len(elem.kids) != 1 {
+			t.Errorf("%s: expected 1 marked-content sequence, got %d", elem.Type, len(elem.kids))
+		}
+	}
+	if len(types) != 3 || types[0] != "H1" || types[1] != "P" || types[2] != "Figure" {
+		t.Errorf("wrong structure %v", types)
+	}
+	if alt := e.structRoot.kids[2].(*StructElem).Alt; alt != "A black square" {
+		t.Errorf("wrong alt text %q", alt)
+	}
+}
+
+func TestStructureNested(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   200,
+		BaseLineSkip: 12,
+		Tagged:       true,
+	}
+
+	sub := &Engine{
+		TextWidth:    200,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		Tagged:       true,
+	}
+	sub.HAddText(fi, "A call-out.")
+	sub.EndParagraph()
+	frame := &Frame{Border: AllSides(1)}
+
+	e.BeginStruct("Sect")
+	e.VAddBox(frame.Box(sub.MakeVTop()))
+	e.EndStruct()
+
+	if _, isTagged := e.vList[0].(*taggedBox); isTagged {
+		t.Error("tagged content was marked again")
+	}
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.structParents) != 1 || len(e.structParents[0]) != 1 {
+		t.Fatalf("wrong parent tree %v", e.structParents)
+	}
+	sect := e.structRoot.kids[0].(*StructElem)
+	if len(sect.kids) != 1 {
+		t.Fatalf("expected 1 child of Sect, got %d", len(sect.kids))
+	}
+	par := sect.kids[0].(*StructElem)
+	if par.Type != "P" || par.parent != sect || len(par.kids) != 1 {
+		t.Errorf("wrong paragraph element %v", par)
+	}
+	if ref := par.kids[0].(*mcRef); ref.page == 0 {
+		t.Error("marked content not assigned to a page")
+	}
+}
+
+func TestDecorationArtifacts(t *testing.T) {
+	fi := testFont(t)
+	e := &Engine{
+		TextWidth:    300,
+		TextHeight:   400,
+		BaseLineSkip: 12,
+		ColumnSep:    12,
+		ColumnRule:   0.5,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		Tagged:       true,
+	}
+
+	e.SetColumns(2)
+	e.HAddText(fi, "Chapter")
+	e.HAddGlue(DotLeaders(fi))
+	e.HAddText(fi, "1")
+	e.EndParagraph()
+
+	frame := &Frame{Border: AllSides(1), Background: color.DeviceGray(0.9)}
+	e.VAddBox(frame.Box(Text(fi, "framed")))
+
+	table := &Table{Rules: RulesAll}
+	cell := TextCell(fi, "cell")
+	cell.Background = color.DeviceGray(0.9)
+	table.AddRow(cell, TextCell(fi, "cell"))
+	table.AddRow(TextCell(fi, "cell"), TextCell(fi, "cell"))
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := builder.New(content.Page, nil, pdf.V1_7)
+	e.makePage(true).Draw(b, 72, 72)
+	if b.Err != nil {
+		t.Fatal(b.Err)
+	}
+
+	// All painting must be marked, either as tagged content or as an
+	// artifact, and artifacts must not be nested inside tagged content.
+	var stack []pdf.Name
+	artifacts := 0
+	for _, op := range b.Stream {
+		switch op.Name {
+		case content.OpBeginMarkedContentWithProperties:
+			tag := op.Args[0].(pdf.Name)
+			if tag == "Artifact" {
+				artifacts++
+				for _, outer := range stack {
+					if outer != "Artifact" {
+						t.Errorf("artifact nested inside %s", outer)
+					}
+				}
+			}
+			stack = append(stack, tag)
+		case content.OpEndMarkedContent:
+			stack = stack[:len(stack)-1]
+		case content.OpFill, content.OpFillEvenOdd, content.OpStroke, content.OpTextShowArray, content.OpTextShow:
+			if len(stack) == 0 {
+				t.Errorf("unmarked %s operator", op.Name)
+			}
+		}
+	}
+	if artifacts == 0 {
+		t.Error("no artifacts found")
+	}
+}
+
+func TestStructureHeadingFigure(t *testing.T) {
+	fi := testFont(t)
+	e := &Engine{
+		TextWidth:    300,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		Tagged:       true,
+	}
+
+	e.VMarkHeading(2, "Introduction")
+	e.HAddText(fi, "Introduction")
+	e.EndParagraph()
+	e.HAddText(fi, "Some text.")
+	e.EndParagraph()
+	e.VAddBox(Graphics(10, 10, 0, func(page *builder.Builder) {}))
+	e.BeginStruct("H1")
+	e.VMarkHeading(1, "Results")
+	e.HAddText(fi, "Results")
+	e.EndParagraph()
+	e.EndStruct()
+
+	var types []string
+	for _, kid := range e.structRoot.kids {
+		types = append(types, kid.(*StructElem).Type)
+	}
+	want := []string{"H2", "P", "Figure", "H1"}
+	if len(types) != len(want) {
+		t.Fatalf("got %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("got %v, want %v", types, want)
+			break
+		}
+	}
+	if h1 := e.structRoot.kids[3].(*StructElem); len(h1.kids) != 0 {
+		t.Errorf("heading element nested inside H1")
+	}
+}
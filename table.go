@@ -0,0 +1,722 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"fmt"
+
+	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// ColumnKind specifies how the width of a table column is determined.
+type ColumnKind int
+
+const (
+	ColumnAuto         ColumnKind = iota // width determined by the cell contents
+	ColumnFixed                          // fixed width
+	ColumnProportional                   // share of the remaining width
+)
+
+// TableColumn describes a column of a table.
+type TableColumn struct {
+	Kind ColumnKind
+
+	// Width is the width of the column for ColumnFixed, and the relative
+	// weight of the column for ColumnProportional.  The field is ignored for
+	// ColumnAuto.
+	Width float64
+
+	Align Alignment // default alignment for the cells in this column
+}
+
+// Alignment specifies the horizontal alignment of material within a cell.
+// The zero value uses the alignment of the column, or AlignLeft if the
+// column does not specify an alignment.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota + 1
+	AlignCenter
+	AlignRight
+)
+
+// VerticalAlignment specifies the vertical alignment of material within a
+// cell.
+type VerticalAlignment int
+
+const (
+	VAlignTop VerticalAlignment = iota
+	VAlignMiddle
+	VAlignBottom
+)
+
+// TableRules selects which rules are drawn in a table.
+type TableRules int
+
+const (
+	RulesFrame   TableRules = 1 << iota // around the table
+	RulesRows                           // between rows
+	RulesColumns                        // between columns
+	RulesHeader                         // below the header rows
+
+	RulesAll = RulesFrame | RulesRows | RulesColumns | RulesHeader
+)
+
+// TableCell is a cell of a table.
+type TableCell struct {
+	// Content adds the contents of the cell to the vertical mode list of
+	// the given Engine.  The TextWidth of the Engine is the width of the cell,
+	// minus the padding, and paragraphs are broken into lines at this width.
+	//
+	// For cells in columns of kind ColumnAuto, Content is called twice:
+	// first with an Engine which only measures the width of the material,
+	// and then with an Engine which typesets it.  Both calls must add the
+	// same material.
+	Content func(e *Engine)
+
+	ColSpan int // number of columns covered by the cell, default 1
+	RowSpan int // number of rows covered by the cell, default 1
+
+	Align      Alignment
+	VAlign     VerticalAlignment
+	Background color.Color // fill colour for the cell, or nil
+}
+
+// TextCell returns a table cell which contains a paragraph of text.
+func TextCell(F *FontInfo, text string) *TableCell {
+	return &TableCell{
+		Content: func(e *Engine) {
+			e.HAddText(F, text)
+			e.EndParagraph()
+		},
+	}
+}
+
+// BoxCell returns a table cell which contains the given box.
+func BoxCell(box Box) *TableCell {
+	return &TableCell{
+		Content: func(e *Engine) {
+			e.VAddBox(box)
+		},
+	}
+}
+
+// Table describes a table, to be added to the vertical mode list using
+// [Engine.VAddTable].
+type Table struct {
+	// Columns describes the columns of the table.  If there are more
+	// columns in the rows than given here, the remaining columns use
+	// ColumnAuto.
+	Columns []TableColumn
+
+	Padding   float64     // space between the cell boundary and its contents
+	Rules     TableRules  // which rules to draw
+	RuleWidth float64     // line width for rules, default 0.5
+	RuleColor color.Color // colour for rules, default black
+
+	// HeaderRows is the number of rows at the top of the table which are
+	// repeated on every page, if the table is broken across pages.
+	HeaderRows int
+
+	rows [][]*TableCell
+}
+
+// AddRow adds a row of cells to the table.  Cells are placed from left to
+// right, skipping columns which are covered by cells from previous rows.
+func (t *Table) AddRow(cells ...*TableCell) {
+	t.rows = append(t.rows, cells)
+}
+
+// tableSlot is a cell, placed in the grid of the table.
+type tableSlot struct {
+	*TableCell
+	row, col   int
+	rows, cols int
+
+	content Box
+}
+
+// place assigns the cells of the table to positions in the grid.  The
+// function returns the placed cells and the number of columns.
+func (t *Table) place() ([]*tableSlot, int) {
+	var slots []*tableSlot
+	nRows := len(t.rows)
+	var covered [][]bool // covered[row][col]
+	isCovered := func(row, col int) bool {
+		return col < len(covered[row]) && covered[row][col]
+	}
+	nCols := len(t.Columns)
+	for row, cells := range t.rows {
+		for len(covered) <= row {
+			covered = append(covered, nil)
+		}
+		col := 0
+		for _, cell := range cells {
+			for isCovered(row, col) {
+				col++
+			}
+			slot := &tableSlot{
+				TableCell: cell,
+				row:       row,
+				col:       col,
+				rows:      min(max(cell.RowSpan, 1), nRows-row),
+				cols:      max(cell.ColSpan, 1),
+			}
+			slots = append(slots, slot)
+			for r := row; r < row+slot.rows; r++ {
+				for len(covered) <= r {
+					covered = append(covered, nil)
+				}
+				for len(covered[r]) < col+slot.cols {
+					covered[r] = append(covered[r], false)
+				}
+				for c := col; c < col+slot.cols; c++ {
+					covered[r][c] = true
+				}
+			}
+			col += slot.cols
+			nCols = max(nCols, col)
+		}
+	}
+	return slots, nCols
+}
+
+// VAddTable adds a table to the vertical mode list.  The table is set to
+// the width of the current column, or narrower if all columns use
+// ColumnAuto and the contents are narrow enough.
+//
+// Tables can be broken across pages between rows, but not inside cells which
+// span several rows.  The header rows are repeated at the top of every
+// page.  Like other splittable boxes, see [Engine.VAddSplittable], tables are
+// only broken when page breaks are chosen page by page.
+//
+// If Tagged is set, the table is tagged as a "Table" structure element, with
+// "TR" elements for the rows and "TH" or "TD" elements for the cells of
+// header rows and other rows.  Repeated header rows are marked as artifacts,
+// and boxes recorded inside them, for example using [Engine.VLabel], are
+// only reported for the first copy.
+//
+// Rows are never split.  If a row, or a group of rows joined by cells which
+// span several rows, does not fit on a page together with the header rows,
+// the table is added anyway and an error is returned.
+func (e *Engine) VAddTable(t *Table) error {
+	slots, nCols := t.place()
+	nRows := len(t.rows)
+	if nRows == 0 || nCols == 0 {
+		return nil
+	}
+
+	columns := make([]TableColumn, nCols)
+	copy(columns, t.Columns)
+	widths := e.tableColumnWidths(t, columns, slots)
+	colX := make([]float64, nCols+1)
+	for i, w := range widths {
+		colX[i+1] = colX[i] + w
+	}
+
+	// For tagged PDF, the table is a "Table" structure element, with a "TR"
+	// element for each row and a "TH" or "TD" element for each cell.
+	var tableElem *StructElem
+	var trElems []*StructElem
+	if e.Tagged {
+		tableElem = e.BeginStruct("Table")
+		trElems = make([]*StructElem, nRows)
+		for r := range trElems {
+			trElems[r] = &StructElem{Type: "TR", parent: tableElem}
+			tableElem.kids = append(tableElem.kids, trElems[r])
+		}
+	}
+
+	// Typeset the cell contents and determine the row heights.
+	rowHeights := make([]float64, nRows)
+	for _, slot := range slots {
+		align := slot.Align
+		if align == 0 {
+			align = columns[slot.col].Align
+		}
+		var elem *StructElem
+		if e.Tagged {
+			typ := "TD"
+			if slot.row < t.HeaderRows {
+				typ = "TH"
+			}
+			tr := trElems[slot.row]
+			elem = &StructElem{Type: typ, parent: tr}
+			tr.kids = append(tr.kids, elem)
+		}
+		width := colX[slot.col+slot.cols] - colX[slot.col] - 2*t.Padding
+		slot.content = e.cellContent(slot.TableCell, max(width, 0), align, elem)
+		if slot.rows == 1 {
+			ext := slot.content.Extent()
+			rowHeights[slot.row] = max(rowHeights[slot.row], ext.Height+ext.Depth+2*t.Padding)
+		}
+	}
+	for _, slot := range slots {
+		if slot.rows == 1 {
+			continue
+		}
+		ext := slot.content.Extent()
+		need := ext.Height + ext.Depth + 2*t.Padding
+		for r := slot.row; r < slot.row+slot.rows; r++ {
+			need -= rowHeights[r]
+		}
+		if need > 0 {
+			rowHeights[slot.row+slot.rows-1] += need
+		}
+	}
+
+	// Build the rows, and determine where the table may be broken.
+	rows := make([]*tableRow, nRows)
+	for r := range rows {
+		rows[r] = &tableRow{
+			BoxExtent: BoxExtent{
+				Width:  colX[nCols],
+				Height: rowHeights[r],
+			},
+			table:      t,
+			nRows:      nRows,
+			nCols:      nCols,
+			colX:       colX,
+			rowHeights: rowHeights[r:],
+		}
+	}
+	canBreak := make([]bool, nRows) // break before row r
+	for r := max(t.HeaderRows+1, 1); r < nRows; r++ {
+		canBreak[r] = true
+	}
+	for _, slot := range slots {
+		rows[slot.row].cells = append(rows[slot.row].cells, slot)
+		for r := slot.row + 1; r < slot.row+slot.rows; r++ {
+			canBreak[r] = false
+		}
+	}
+
+	var header []Box
+	var contents []Box
+	for r, row := range rows {
+		if r > 0 {
+			if canBreak[r] {
+				contents = append(contents, penalty(0))
+			} else {
+				contents = append(contents, penalty(PenaltyPreventBreak))
+			}
+		}
+		contents = append(contents, row)
+		if r < t.HeaderRows {
+			header = append(header, row)
+		}
+	}
+
+	e.VAddSplittable(VBox(contents...), t.frame(e, header))
+	if tableElem != nil {
+		e.EndStruct()
+	}
+
+	return t.checkHeight(e.newTextHeight(), rowHeights, canBreak)
+}
+
+// checkHeight returns an error if a group of rows, between two places where
+// the table can be broken, does not fit into the given height together with
+// the repeated header rows.
+func (t *Table) checkHeight(height float64, rowHeights []float64, canBreak []bool) error {
+	if height <= 0 {
+		return nil
+	}
+	var header float64
+	for _, h := range rowHeights[:min(t.HeaderRows, len(rowHeights))] {
+		header += h
+	}
+	var group float64
+	start := 0
+	for r, h := range rowHeights {
+		if canBreak[r] {
+			group, start = 0, r
+		}
+		group += h
+		need := group
+		if start > 0 {
+			need += header
+		}
+		if need > height+eps {
+			return fmt.Errorf("table row %d does not fit on a page", r+1)
+		}
+	}
+	return nil
+}
+
+// frame returns a SplitFrame which repeats the header rows on every page and
+// draws the frame around the table.
+func (t *Table) frame(e *Engine, header []Box) SplitFrame {
+	return func(part Box, first, last bool) Box {
+		var rows []Box
+		if !first {
+			for _, row := range header {
+				rows = append(rows, &repeatedBox{Box: row, e: e})
+			}
+		}
+		if vb, ok := part.(*vBox); ok {
+			rows = append(rows, vb.Contents...)
+		} else {
+			rows = append(rows, part)
+		}
+		box := VBox(rows...)
+		if t.Rules&RulesFrame == 0 {
+			return box
+		}
+		return &tableFrame{Box: box, table: t}
+	}
+}
+
+// tableColumnWidths determines the widths of the table columns, such that
+// the table fits into the current column where possible.
+func (e *Engine) tableColumnWidths(t *Table, columns []TableColumn, slots []*tableSlot) []float64 {
+	nCols := len(columns)
+	widths := make([]float64, nCols)
+	lo := make([]float64, nCols)
+	hi := make([]float64, nCols)
+
+	avail := e.ColumnWidth()
+	var weights float64
+	for i, col := range columns {
+		switch col.Kind {
+		case ColumnFixed:
+			widths[i] = col.Width
+			avail -= col.Width
+		case ColumnProportional:
+			weights += max(col.Width, 0)
+		}
+	}
+
+	// Measure the contents of cells in auto-width columns, starting with
+	// cells which span a single column.
+	var multi []*tableSlot
+	measured := make(map[*tableSlot]*cellWidths)
+	for _, slot := range slots {
+		hasAuto := false
+		for c := slot.col; c < slot.col+slot.cols; c++ {
+			hasAuto = hasAuto || columns[c].Kind == ColumnAuto
+		}
+		if !hasAuto {
+			continue
+		}
+		m := measureCell(slot.TableCell)
+		m.min += 2 * t.Padding
+		m.max += 2 * t.Padding
+		measured[slot] = m
+		if slot.cols > 1 {
+			multi = append(multi, slot)
+			continue
+		}
+		lo[slot.col] = max(lo[slot.col], m.min)
+		hi[slot.col] = max(hi[slot.col], m.max)
+	}
+	for _, slot := range multi {
+		// Distribute any missing width evenly over the auto-width columns
+		// covered by the cell.
+		m := measured[slot]
+		var nAuto int
+		var haveLo, haveHi float64
+		for c := slot.col; c < slot.col+slot.cols; c++ {
+			if columns[c].Kind == ColumnAuto {
+				nAuto++
+				haveLo += lo[c]
+				haveHi += hi[c]
+			} else {
+				haveLo += widths[c]
+				haveHi += widths[c]
+			}
+		}
+		for c := slot.col; c < slot.col+slot.cols; c++ {
+			if columns[c].Kind != ColumnAuto {
+				continue
+			}
+			if m.min > haveLo {
+				lo[c] += (m.min - haveLo) / float64(nAuto)
+			}
+			if m.max > haveHi {
+				hi[c] += (m.max - haveHi) / float64(nAuto)
+			}
+			hi[c] = max(hi[c], lo[c])
+		}
+	}
+
+	// Auto-width columns get their natural width, if there is enough space,
+	// and are narrowed towards their minimal width otherwise.
+	var totalLo, totalHi float64
+	for i, col := range columns {
+		if col.Kind == ColumnAuto {
+			totalLo += lo[i]
+			totalHi += hi[i]
+		}
+	}
+	q := 1.0
+	if totalHi > avail && totalHi > totalLo {
+		q = max((avail-totalLo)/(totalHi-totalLo), 0)
+	}
+	for i, col := range columns {
+		if col.Kind == ColumnAuto {
+			widths[i] = lo[i] + q*(hi[i]-lo[i])
+			avail -= widths[i]
+		}
+	}
+
+	// Proportional columns share the remaining space.
+	if weights > 0 && avail > 0 {
+		for i, col := range columns {
+			if col.Kind == ColumnProportional {
+				widths[i] = avail * max(col.Width, 0) / weights
+			}
+		}
+	}
+	return widths
+}
+
+// cellWidths gives the minimal and the natural width of the contents of a
+// table cell.
+type cellWidths struct {
+	min, max float64
+}
+
+// measureCell determines the minimal and natural width of the contents of a
+// table cell.
+func measureCell(cell *TableCell) *cellWidths {
+	m := &cellWidths{}
+	if cell.Content != nil {
+		e := &Engine{measure: m}
+		cell.Content(e)
+	}
+	return m
+}
+
+// addParagraph records the widths of a paragraph.  The minimal width is
+// the width of the widest word, and the natural width is the width of the
+// paragraph set on a single line.
+func (m *cellWidths) addParagraph(e *Engine, hList []any) {
+	var skip float64
+	if e.LeftSkip != nil {
+		skip += e.LeftSkip.Length
+	}
+	if e.RightSkip != nil {
+		skip += e.RightSkip.Length
+	}
+
+	var line, word float64
+	for _, item := range hList {
+		switch h := item.(type) {
+		case *hModeBox:
+			line += h.width
+			word += h.width
+			m.min = max(m.min, word+skip)
+		case *Glue:
+			line += h.Length
+			word = 0
+		case *hModePenalty:
+			if h.Penalty < PenaltyPreventBreak {
+				word = h.width
+			}
+		}
+	}
+	m.max = max(m.max, line+skip)
+}
+
+// addBox records the width of a box in the vertical mode list.
+func (m *cellWidths) addBox(width float64) {
+	m.min = max(m.min, width)
+	m.max = max(m.max, width)
+}
+
+// cellContent typesets the contents of a table cell, using the given width.
+// If elem is not nil, the contents are tagged as belonging to elem.
+func (e *Engine) cellContent(cell *TableCell, width float64, align Alignment, elem *StructElem) Box {
+	if cell.Content == nil {
+		return VBox()
+	}
+
+	fill := Skip(0, 1, 1, 0, 0)
+	sub := &Engine{
+		TextWidth:     width,
+		BaseLineSkip:  e.BaseLineSkip,
+		ParSkip:       e.ParSkip,
+		HyphenPenalty: e.HyphenPenalty,
+		CrossRefs:     e.CrossRefs,
+		Tagged:        elem != nil,
+		parent:        e,
+		structCur:     elem,
+	}
+	switch align {
+	case AlignCenter:
+		sub.LeftSkip, sub.RightSkip = fill, fill
+	case AlignRight:
+		sub.LeftSkip = fill
+	default:
+		sub.RightSkip = fill
+	}
+	cell.Content(sub)
+
+	// Align boxes which are narrower than the cell.
+	var contents []Box
+	for _, box := range sub.vList {
+		ext := box.Extent()
+		if ext.WhiteSpaceOnly || ext.Width >= width {
+			contents = append(contents, box)
+			continue
+		}
+		switch align {
+		case AlignCenter:
+			box = HBoxTo(width, fill, box, fill)
+		case AlignRight:
+			box = HBoxTo(width, fill, box)
+		}
+		contents = append(contents, box)
+	}
+	return VBox(contents...)
+}
+
+// tableRow is a row of a table.  The row draws the cells which start in this
+// row, including cells which extend into the following rows.
+type tableRow struct {
+	BoxExtent
+
+	table        *Table
+	cells        []*tableSlot
+	nRows, nCols int
+	colX         []float64 // left edges of the columns, and the right edge
+	rowHeights   []float64 // heights of this row and the following rows
+
+	e *Engine // for tagged PDF, see Engine.markArtifacts
+}
+
+// Draw implements the [Box] interface.
+func (obj *tableRow) Draw(page *builder.Builder, xPos, yPos float64) {
+	t := obj.table
+	top := yPos + obj.Height
+	for _, slot := range obj.cells {
+		x0 := xPos + obj.colX[slot.col]
+		x1 := xPos + obj.colX[slot.col+slot.cols]
+		height := obj.slotHeight(slot)
+		y0 := top - height
+
+		if slot.Background != nil {
+			decorate(obj.e, page, func() {
+				page.PushGraphicsState()
+				page.SetFillColor(slot.Background)
+				page.Rectangle(x0, y0, x1-x0, height)
+				page.Fill()
+				page.PopGraphicsState()
+			})
+		}
+
+		cx, cy := obj.contentPos(slot, xPos, yPos)
+		slot.content.Draw(page, cx, cy)
+
+		lastRow := slot.row+slot.rows == obj.nRows
+		headerEnd := slot.row+slot.rows == t.HeaderRows
+		drawRight := t.Rules&RulesColumns != 0 && slot.col+slot.cols < obj.nCols
+		drawBottom := !lastRow && (t.Rules&RulesRows != 0 || headerEnd && t.Rules&RulesHeader != 0)
+		if drawRight || drawBottom {
+			decorate(obj.e, page, func() {
+				t.setupRules(page)
+				if drawRight {
+					page.MoveTo(x1, y0)
+					page.LineTo(x1, top)
+				}
+				if drawBottom {
+					page.MoveTo(x0, y0)
+					page.LineTo(x1, y0)
+				}
+				page.Stroke()
+				page.PopGraphicsState()
+			})
+		}
+	}
+}
+
+// slotHeight returns the height of the given cell, including the rows
+// spanned by the cell.
+func (obj *tableRow) slotHeight(slot *tableSlot) float64 {
+	height := 0.0
+	for _, h := range obj.rowHeights[:slot.rows] {
+		height += h
+	}
+	return height
+}
+
+// contentPos returns the position of the reference point of the contents
+// of the given cell, when the row is drawn at (xPos, yPos).
+func (obj *tableRow) contentPos(slot *tableSlot, xPos, yPos float64) (float64, float64) {
+	t := obj.table
+	ext := slot.content.Extent()
+	free := obj.slotHeight(slot) - 2*t.Padding - ext.Height - ext.Depth
+	var shift float64
+	switch slot.VAlign {
+	case VAlignMiddle:
+		shift = free / 2
+	case VAlignBottom:
+		shift = free
+	}
+	top := yPos + obj.Height
+	return xPos + obj.colX[slot.col] + t.Padding, top - t.Padding - shift - ext.Height
+}
+
+func (obj *tableRow) children() []Box {
+	res := make([]Box, len(obj.cells))
+	for i, slot := range obj.cells {
+		res[i] = slot.content
+	}
+	return res
+}
+
+// setupRules saves the graphics state and sets the line width and colour
+// for drawing rules.
+func (t *Table) setupRules(page *builder.Builder) {
+	page.PushGraphicsState()
+	lw := t.RuleWidth
+	if lw <= 0 {
+		lw = 0.5
+	}
+	page.SetLineWidth(lw)
+	if t.RuleColor != nil {
+		page.SetStrokeColor(t.RuleColor)
+	} else {
+		page.SetStrokeColor(color.Black)
+	}
+}
+
+// tableFrame draws the frame around (a part of) a table.
+type tableFrame struct {
+	Box
+	table *Table
+	e     *Engine // for tagged PDF, see Engine.markArtifacts
+}
+
+// Draw implements the [Box] interface.
+func (obj *tableFrame) Draw(page *builder.Builder, xPos, yPos float64) {
+	obj.Box.Draw(page, xPos, yPos)
+
+	ext := obj.Extent()
+	decorate(obj.e, page, func() {
+		obj.table.setupRules(page)
+		page.Rectangle(xPos, yPos-ext.Depth, ext.Width, ext.Height+ext.Depth)
+		page.Stroke()
+		page.PopGraphicsState()
+	})
+}
+
+func (obj *tableFrame) children() []Box {
+	return []Box{obj.Box}
+}
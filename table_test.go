@@ -0,0 +1,293 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+func TestTablePlace(t *testing.T) {
+	cell := func(cols, rows int) *TableCell {
+		return &TableCell{ColSpan: cols, RowSpan: rows}
+	}
+	table := &Table{}
+	table.AddRow(cell(1, 2), cell(2, 1))
+	table.AddRow(cell(1, 1), cell(1, 1))
+	table.AddRow(cell(3, 1))
+
+	slots, nCols := table.place()
+	if nCols != 3 {
+		t.Errorf("expected 3 columns, got %d", nCols)
+	}
+	type pos struct{ row, col, rows, cols int }
+	want := []pos{
+		{0, 0, 2, 1}, {0, 1, 1, 2},
+		{1, 1, 1, 1}, {1, 2, 1, 1},
+		{2, 0, 1, 3},
+	}
+	if len(slots) != len(want) {
+		t.Fatalf("expected %d cells, got %d", len(want), len(slots))
+	}
+	for i, slot := range slots {
+		got := pos{slot.row, slot.col, slot.rows, slot.cols}
+		if got != want[i] {
+			t.Errorf("cell %d: got %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestTableColumnWidths(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{TextWidth: 300}
+	table := &Table{
+		Columns: []TableColumn{
+			{Kind: ColumnFixed, Width: 50},
+			{Kind: ColumnAuto},
+			{Kind: ColumnProportional, Width: 1},
+			{Kind: ColumnProportional, Width: 2},
+		},
+		Padding: 2,
+	}
+	table.AddRow(TextCell(fi, "a"), TextCell(fi, "word"), TextCell(fi, "b"), TextCell(fi, "c"))
+
+	slots, nCols := table.place()
+	columns := make([]TableColumn, nCols)
+	copy(columns, table.Columns)
+	widths := e.tableColumnWidths(table, columns, slots)
+
+	natural := Text(fi, "word").Extent().Width + 4
+	if math.Abs(widths[1]-natural) > 1e-6 {
+		t.Errorf("auto column: got %g, want %g", widths[1], natural)
+	}
+	if widths[0] != 50 {
+		t.Errorf("fixed column: got %g, want 50", widths[0])
+	}
+	if math.Abs(2*widths[2]-widths[3]) > 1e-6 {
+		t.Errorf("wrong proportions %g:%g", widths[2], widths[3])
+	}
+	total := widths[0] + widths[1] + widths[2] + widths[3]
+	if math.Abs(total-300) > 1e-6 {
+		t.Errorf("total width %g, want 300", total)
+	}
+
+	// Long text in an auto-width column is broken into lines.
+	table = &Table{}
+	table.AddRow(TextCell(fi, "some rather long text which does not fit on a single line of the table, since the table is only three hundred points wide"))
+	slots, nCols = table.place()
+	widths = e.tableColumnWidths(table, make([]TableColumn, nCols), slots)
+	if math.Abs(widths[0]-300) > 1e-6 {
+		t.Errorf("auto column: got %g, want 300", widths[0])
+	}
+}
+
+func TestTablePageBreak(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	pages := 0
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   100,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		AfterPageFunc: func(int, *builder.Builder) error {
+			pages++
+			return nil
+		},
+	}
+
+	var headerPages []int
+	table := &Table{
+		Padding:    2,
+		Rules:      RulesAll,
+		HeaderRows: 1,
+	}
+	table.AddRow(&TableCell{
+		Content: func(e *Engine) {
+			e.VRecordNextBox(func(bi *BoxInfo) {
+				headerPages = append(headerPages, bi.PageNo)
+			})
+			e.VAddBox(Text(fi, "Header"))
+		},
+	}, TextCell(fi, "Value"))
+	for range 20 {
+		table.AddRow(TextCell(fi, "item"), TextCell(fi, "1.00"))
+	}
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pages < 2 {
+		t.Fatalf("expected the table to be broken across pages, got %d pages", pages)
+	}
+	// The repeated copies of the header are not recorded.
+	if len(headerPages) != 1 || headerPages[0] != 1 {
+		t.Errorf("header recorded on pages %v", headerPages)
+	}
+}
+
+func TestTableLabel(t *testing.T) {
+	doc, _ := newTestDoc(t)
+	var pages []int
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   50,
+		BaseLineSkip: 12,
+		AfterPageFunc: func(pageNo int, page *builder.Builder) error {
+			pages = append(pages, pageNo)
+			return nil
+		},
+	}
+
+	table := &Table{}
+	for range 10 {
+		table.AddRow(BoxCell(Rule(20, 10, 0)))
+	}
+	e.VLabel("tab")
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) < 2 {
+		t.Errorf("labelled table was not split, %d pages", len(pages))
+	}
+	if pageNo, ok := e.LabelPage("tab"); !ok || pageNo != 1 {
+		t.Errorf("label on page %d, %t", pageNo, ok)
+	}
+}
+
+func TestTableTagged(t *testing.T) {
+	fi := testFont(t)
+	doc, _ := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   100,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+		Tagged:       true,
+	}
+
+	table := &Table{HeaderRows: 1}
+	table.AddRow(TextCell(fi, "Header"), TextCell(fi, "Value"))
+	table.AddRow(&TableCell{
+		Content: func(e *Engine) {
+			e.VMarkHeading(1, "First item")
+			e.VAddBox(Text(fi, "first"))
+		},
+	}, TextCell(fi, "1.00"))
+	for range 19 {
+		table.AddRow(TextCell(fi, "item"), TextCell(fi, "1.00"))
+	}
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h := e.Headings(); len(h) != 1 || h[0].pageNo() != 1 {
+		t.Errorf("heading in table cell not recorded: %v", h)
+	}
+
+	if len(e.structRoot.kids) != 1 {
+		t.Fatalf("expected 1 element, got %d", len(e.structRoot.kids))
+	}
+	tableElem := e.structRoot.kids[0].(*StructElem)
+	if tableElem.Type != "Table" || len(tableElem.kids) != 21 {
+		t.Fatalf("wrong table element %s with %d rows", tableElem.Type, len(tableElem.kids))
+	}
+	for r, kid := range tableElem.kids {
+		tr := kid.(*StructElem)
+		if tr.Type != "TR" || len(tr.kids) != 2 {
+			t.Fatalf("row %d: wrong element %s with %d cells", r, tr.Type, len(tr.kids))
+		}
+		want := "TD"
+		if r == 0 {
+			want = "TH"
+		}
+		for _, kid := range tr.kids {
+			cell := kid.(*StructElem)
+			if cell.Type != want {
+				t.Errorf("row %d: got %s, want %s", r, cell.Type, want)
+			}
+			// repeated header rows are artifacts
+			if len(cell.kids) != 1 {
+				t.Errorf("row %d: %d marked-content sequences", r, len(cell.kids))
+			}
+		}
+	}
+}
+
+func TestTableTooTall(t *testing.T) {
+	e := &Engine{
+		TextWidth:  300,
+		TextHeight: 50,
+	}
+
+	table := &Table{HeaderRows: 1}
+	table.AddRow(BoxCell(Rule(20, 20, 0)))
+	table.AddRow(BoxCell(Rule(20, 20, 0)))
+	table.AddRow(BoxCell(Rule(20, 20, 0)))
+	err := e.VAddTable(table)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The new row does not fit on a page together with the header.
+	table.AddRow(BoxCell(Rule(20, 40, 0)))
+	err = e.VAddTable(table)
+	if err == nil {
+		t.Error("row taller than the page not detected")
+	}
+	if len(e.vList) == 0 {
+		t.Error("table not added")
+	}
+}
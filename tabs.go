@@ -0,0 +1,220 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"slices"
+	"strings"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// TabKind specifies how text is aligned at a tab stop.
+type TabKind int
+
+const (
+	TabLeft    TabKind = iota // the text starts at the tab stop
+	TabRight                  // the text ends at the tab stop
+	TabCenter                 // the text is centred on the tab stop
+	TabDecimal                // the decimal separator is at the tab stop
+)
+
+// TabStop describes a tab stop.
+type TabStop struct {
+	// Pos is the position of the tab stop, measured from the left edge of
+	// the column.
+	Pos float64
+
+	Kind TabKind
+
+	// Decimal is the decimal separator for TabDecimal.  If this is zero,
+	// '.' is used.
+	Decimal rune
+
+	// Leader (optional) is repeated to fill the space before the tab stop,
+	// see [Leaders].
+	Leader Box
+}
+
+// SetTabStops sets the tab stops for the current paragraph.  The tab stops
+// are cleared at the end of the paragraph.
+//
+// Tabs are added using [Engine.HAddTab], or as tab characters in
+// [Engine.HAddText].  The text between two tabs is aligned according to the
+// first tab stop to the right of the current position.  A tab with no tab
+// stop to its right is ignored.  Tab positions are computed using the
+// natural width of the material on the line.  The space before the last tab
+// on a line is not stretched or shrunk when the line is justified, and
+// neither is the space after the last tab unless this tab is a TabLeft stop.
+//
+// Tabs are resolved after the paragraph has been broken into lines, so the
+// line breaker does not see the space added for tabs.  Lines with tabs can
+// therefore be overfull, if the material does not fit in front of the tab
+// stops.  Tab stops work best for lines which are not broken, like the
+// entries of a table of contents.
+func (e *Engine) SetTabStops(stops ...TabStop) {
+	e.tabStops = slices.Clone(stops)
+	slices.SortStableFunc(e.tabStops, func(a, b TabStop) int {
+		switch {
+		case a.Pos < b.Pos:
+			return -1
+		case a.Pos > b.Pos:
+			return +1
+		default:
+			return 0
+		}
+	})
+}
+
+// HAddTab adds a tab to the horizontal mode list.
+func (e *Engine) HAddTab() {
+	if len(e.hList) == 0 && e.ParIndent != nil {
+		e.hList = append(e.hList, e.ParIndent)
+	}
+	e.hList = append(e.hList, &hModeBox{Box: tabMark{}})
+}
+
+// tabMark marks the position of a tab in the horizontal mode list.
+type tabMark struct{}
+
+func (obj tabMark) Extent() *BoxExtent {
+	return &BoxExtent{}
+}
+
+func (obj tabMark) Draw(page *builder.Builder, xPos, yPos float64) {
+	// pass
+}
+
+// resolveTabs replaces the tabs in a line by space or leaders, so that the
+// material after each tab is aligned at the next tab stop.
+func resolveTabs(line []Box, stops []TabStop) []Box {
+	if !slices.ContainsFunc(line, isTab) {
+		return line
+	}
+
+	// Glue which is set after the tabs have been resolved must not move
+	// material which is aligned at a tab stop.  Glue at the end of the line,
+	// for example ParFillSkip or RightSkip, is left alone.
+	last := len(line) - 1
+	for !isTab(line[last]) {
+		last--
+	}
+	tailEnd := len(line)
+	for tailEnd > last+1 {
+		if _, ok := line[tailEnd-1].(*Glue); !ok {
+			break
+		}
+		tailEnd--
+	}
+	rigidTail := false
+
+	res := make([]Box, 0, len(line))
+	x := 0.0
+	for i, box := range line {
+		if !isTab(box) {
+			if g, ok := box.(*Glue); ok && (i < last || rigidTail && i < tailEnd) {
+				box = g.rigid()
+			}
+			res = append(res, box)
+			x += box.Extent().Width
+			continue
+		}
+
+		k := slices.IndexFunc(stops, func(stop TabStop) bool {
+			return stop.Pos > x+eps
+		})
+		if k < 0 {
+			// no tab stop to the right, the tab is ignored
+			continue
+		}
+		stop := stops[k]
+
+		end := len(line)
+		if j := slices.IndexFunc(line[i+1:], isTab); j >= 0 {
+			end = i + 1 + j
+		}
+		segment := line[i+1 : end]
+
+		target := stop.Pos
+		switch stop.Kind {
+		case TabRight:
+			target -= naturalWidth(segment)
+		case TabCenter:
+			target -= naturalWidth(segment) / 2
+		case TabDecimal:
+			sep := stop.Decimal
+			if sep == 0 {
+				sep = '.'
+			}
+			target -= decimalOffset(segment, sep)
+		}
+		if i == last && stop.Kind != TabLeft {
+			rigidTail = true
+		}
+		gap := max(target-x, 0)
+		if stop.Leader != nil {
+			res = append(res, &fixedLeaders{glue: Leaders(stop.Leader, &Glue{}), width: gap})
+		} else {
+			res = append(res, Kern(gap))
+		}
+		x += gap
+	}
+	return res
+}
+
+// rigid returns a copy of g which can neither stretch nor shrink.
+func (g *Glue) rigid() *Glue {
+	res := g.Clone()
+	res.Stretch = glueAmount{}
+	res.Shrink = glueAmount{}
+	return res
+}
+
+func isTab(box Box) bool {
+	_, ok := box.(tabMark)
+	return ok
+}
+
+func naturalWidth(boxes []Box) float64 {
+	var width float64
+	for _, box := range boxes {
+		width += box.Extent().Width
+	}
+	return width
+}
+
+// decimalOffset returns the distance from the start of the boxes to the
+// first occurrence of the decimal separator.  If there is no separator, the
+// total width is returned.
+func decimalOffset(boxes []Box, sep rune) float64 {
+	var x float64
+	for _, box := range boxes {
+		text, ok := box.(*TextBox)
+		if !ok {
+			x += box.Extent().Width
+			continue
+		}
+		x += text.Glyphs.Skip
+		for _, g := range text.Glyphs.Seq {
+			if strings.ContainsRune(g.Text, sep) {
+				return x
+			}
+			x += g.Advance
+		}
+	}
+	return x
+}
@@ -0,0 +1,137 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTabStops(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{
+		TextWidth:   300,
+		ParFillSkip: Skip(0, 1, 1, 0, 0),
+	}
+	e.SetTabStops(
+		TabStop{Pos: 150, Kind: TabDecimal},
+		TabStop{Pos: 250, Kind: TabRight, Leader: Text(fi, ".")},
+	)
+	e.HAddText(fi, "Tea\t12.5\t3.50")
+	e.EndParagraph()
+	if len(e.tabStops) != 0 {
+		t.Error("tab stops not cleared at the end of the paragraph")
+	}
+
+	line := e.vList[0].(*hBox)
+	text, pos := glyphPositions(line)
+	if text != "Tea12.53.50" {
+		t.Fatalf("wrong text %q", text)
+	}
+	if x := pos[5]; math.Abs(x-150) > 1e-6 {
+		t.Errorf("decimal point at %g, want 150", x)
+	}
+	right := pos[10] + Text(fi, "0").Extent().Width
+	if math.Abs(right-250) > 1e-6 {
+		t.Errorf("right edge at %g, want 250", right)
+	}
+
+	var leaders int
+	for _, box := range line.Contents {
+		if _, ok := box.(*fixedLeaders); ok {
+			leaders++
+		}
+	}
+	if leaders != 1 {
+		t.Errorf("expected 1 leader, got %d", leaders)
+	}
+}
+
+func TestTabsJustified(t *testing.T) {
+	fi := testFont(t)
+
+	for _, kind := range []TabKind{TabLeft, TabRight} {
+		// Without ParFillSkip, the only line of the paragraph is justified.
+		e := &Engine{
+			TextWidth: 300,
+		}
+		e.SetTabStops(TabStop{Pos: 150, Kind: kind})
+		e.HAddText(fi, "one two\tthree four")
+		e.EndParagraph()
+
+		line := e.vList[0].(*hBox)
+		text, pos := glyphPositions(line)
+		k := strings.Index(text, "three")
+		if k < 0 || len(pos) != len(text) {
+			t.Fatalf("wrong text %q", text)
+		}
+		x := pos[k]
+		if kind == TabRight {
+			x = pos[len(pos)-1] + Text(fi, "r").Extent().Width
+		}
+		if math.Abs(x-150) > 1e-6 {
+			t.Errorf("%d: text aligned at %g, want 150", kind, x)
+		}
+	}
+}
+
+func TestTabNoStop(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{
+		TextWidth:   300,
+		ParFillSkip: Skip(0, 1, 1, 0, 0),
+	}
+	e.SetTabStops(TabStop{Pos: 50})
+	e.HAddText(fi, "A\tB\tC")
+	e.EndParagraph()
+
+	// The second tab has no stop to its right and is ignored.
+	text, pos := glyphPositions(e.vList[0].(*hBox))
+	if text != "ABC" {
+		t.Fatalf("wrong text %q", text)
+	}
+	if math.Abs(pos[1]-50) > 1e-6 {
+		t.Errorf("B at %g, want 50", pos[1])
+	}
+	if want := pos[1] + Text(fi, "B").Extent().Width; math.Abs(pos[2]-want) > 1e-6 {
+		t.Errorf("C at %g, want %g", pos[2], want)
+	}
+}
+
+// glyphPositions returns the text on a line, together with the horizontal
+// positions of all glyphs.
+func glyphPositions(line *hBox) (string, []float64) {
+	xx := horizontalLayout(0, line.Width, line.Contents...)
+	var text string
+	var pos []float64
+	for i, box := range line.Contents {
+		tb, ok := box.(*TextBox)
+		if !ok {
+			continue
+		}
+		x := xx[i] + tb.Glyphs.Skip
+		for _, g := range tb.Glyphs.Seq {
+			text += g.Text
+			pos = append(pos, x)
+			x += g.Advance
+		}
+	}
+	return text, pos
+}
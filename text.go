@@ -20,8 +20,10 @@ import (
 	"math"
 
 	"seehuhn.de/go/pdf/font"
+	"seehuhn.de/go/pdf/graphics"
 	"seehuhn.de/go/pdf/graphics/color"
 	"seehuhn.de/go/pdf/graphics/content/builder"
+	"seehuhn.de/go/pdf/property"
 )
 
 // TextBox represents a typeset string of characters as a Box object.
@@ -94,3 +96,31 @@ func (obj *TextBox) Draw(page *builder.Builder, xPos, yPos float64) {
 	page.TextShowGlyphs(obj.Glyphs)
 	page.TextEnd()
 }
+
+// ActualText returns a box which draws box, but which is replaced by the
+// given text when text is extracted from the PDF file.  This can be used for
+// material where the glyphs do not map to the intended text, for example for
+// decorative initials or for text drawn using graphics.
+func ActualText(box Box, text string) Box {
+	return &actualTextBox{Box: box, text: text}
+}
+
+type actualTextBox struct {
+	Box
+	text string
+}
+
+// Draw implements the [Box] interface.
+func (obj *actualTextBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	page.MarkedContentStart(&graphics.MarkedContent{
+		Tag:        "Span",
+		Properties: &property.ActualText{Text: obj.text, SingleUse: true},
+		Inline:     true,
+	})
+	obj.Box.Draw(page, xPos, yPos)
+	page.MarkedContentEnd()
+}
+
+func (obj *actualTextBox) children() []Box {
+	return []Box{obj.Box}
+}
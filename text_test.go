@@ -0,0 +1,173 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/font"
+	"seehuhn.de/go/pdf/font/textextract"
+	"seehuhn.de/go/pdf/page"
+	"seehuhn.de/go/pdf/pagetree"
+	"seehuhn.de/go/pdf/reader"
+)
+
+func TestTextExtraction(t *testing.T) {
+	fi := testFont(t)
+
+	e := &Engine{
+		TextWidth:   40,
+		RightSkip:   Skip(0, 1, 1, 0, 0),
+		ParFillSkip: Skip(0, 1, 1, 0, 0),
+	}
+	e.HAddText(fi, "Hi,  \n all­the­words.")
+	e.EndParagraph()
+
+	var text strings.Builder
+	hyphens := 0
+	for _, box := range e.vList {
+		line := boxText(box)
+		if strings.HasSuffix(line, "\u00AD") {
+			hyphens++
+			line = strings.TrimSuffix(line, "\u00AD")
+		}
+		if strings.ContainsRune(line, 0x00AD) {
+			t.Errorf("soft hyphen inside line %q", line)
+		}
+		text.WriteString(line)
+	}
+	if hyphens == 0 {
+		t.Error("no line ends at a soft hyphen")
+	}
+	if got, want := text.String(), "Hi, allthewords."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLigatureExtraction(t *testing.T) {
+	fi := testFont(t)
+	doc, buf := newTestDoc(t)
+	e := &Engine{
+		PageSize:     document.A4,
+		TextWidth:    300,
+		TextHeight:   100,
+		BaseLineSkip: 12,
+		ParFillSkip:  Skip(0, 1, 1, 0, 0),
+	}
+	e.HAddText(fi, "office files")
+	e.EndParagraph()
+
+	// The font combines "fi" into a single glyph, which must carry the
+	// text of both characters.
+	ligature := false
+	for _, g := range e.vList[0].(*hBox).Contents[0].(*TextBox).Glyphs.Seq {
+		if g.Text == "fi" {
+			ligature = true
+		}
+	}
+	if !ligature {
+		t.Fatal("no ligature formed")
+	}
+
+	err := e.AppendPages(doc.Tree, doc.RM, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Extract the text from the PDF file.
+	r, err := pdf.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, pageDict, err := pagetree.GetPage(r, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := pdf.NewExtractor(r)
+	pg, err := pdf.Decode(pdf.CursorAt(x, nil), pageDict, page.Decode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text strings.Builder
+	rd := reader.New(x)
+	rd.TextEvent = func(event reader.TextEvent, arg float64) {
+		if event == reader.TextEventSpace {
+			text.WriteString(" ")
+		}
+	}
+	rd.Character = func(c font.Code) error {
+		if c.Text != "" {
+			text.WriteString(c.Text)
+		} else {
+			// Glyphs without ToUnicode entries are identified by their
+			// names, as in a text extraction tool.
+			text.WriteString(textextract.GlyphNameMapping(rd.State.GState.TextFont)[c.CID])
+		}
+		return nil
+	}
+	err = rd.ProcessPage(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(strings.Fields(text.String()), " "), "office files"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// noSpaceFont is a font without a glyph for the space character.
+type noSpaceFont struct {
+	font.Layouter
+}
+
+func (f noSpaceFont) Layout(seq *font.GlyphSeq, ptSize float64, s string) *font.GlyphSeq {
+	return f.Layouter.Layout(seq, ptSize, strings.ReplaceAll(s, " ", ""))
+}
+
+func TestTextNoSpaceGlyph(t *testing.T) {
+	fi := testFont(t)
+	fi.Font = noSpaceFont{fi.Font}
+
+	e := &Engine{
+		TextWidth:   300,
+		ParFillSkip: Skip(0, 1, 1, 0, 0),
+	}
+	e.HAddText(fi, "A")
+	e.HAddBox(Rule(5, 5, 0))
+	e.HAddText(fi, " B C")
+	e.EndParagraph()
+
+	var text strings.Builder
+	for _, box := range e.vList[0].(*hBox).Contents {
+		if tb, ok := box.(*TextBox); ok {
+			for _, g := range tb.Glyphs.Seq {
+				text.WriteString(g.Text)
+			}
+		}
+	}
+	if got, want := text.String(), "A B C"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+// VAddTableOfContents adds a table of contents to the vertical mode list.
+// Every heading is set as a separate paragraph, with the page number flush
+// right, separated from the title by dot leaders.  Nested headings are
+// indented by indent per level.
+//
+// If CrossRefs is set, the headings from the previous layout pass are used.
+// Otherwise, the headings marked so far are listed, and headings which have
+// not yet been placed on a page are shown with "??" as the page number.
+func (e *Engine) VAddTableOfContents(F *FontInfo, indent float64) {
+	headings := e.headings
+	if e.CrossRefs != nil {
+		headings = e.CrossRefs.prevHeadings
+	}
+	if len(headings) == 0 {
+		return
+	}
+
+	minLevel := headings[0].Level
+	for _, h := range headings[1:] {
+		minLevel = min(minLevel, h.Level)
+	}
+
+	parIndent, leftSkip, parFillSkip, parSkip := e.ParIndent, e.LeftSkip, e.ParFillSkip, e.ParSkip
+	defer func() {
+		e.ParIndent, e.LeftSkip, e.ParFillSkip, e.ParSkip = parIndent, leftSkip, parFillSkip, parSkip
+	}()
+	e.ParIndent = nil
+	e.ParFillSkip = nil
+
+	space := Skip(F.Size/4, 0, 0, 0, 0)
+	for i, h := range headings {
+		if i > 0 {
+			e.ParSkip = nil
+		}
+		e.LeftSkip = (&Glue{Length: float64(h.Level-minLevel) * indent}).Plus(leftSkip)
+
+		e.HAddText(F, h.Title)
+		e.HAddGlue(space)
+		e.HAddGlue(DotLeaders(F))
+		e.HAddGlue(space)
+		e.HAddText(F, pageText(h.pageNo(), h.Info != nil))
+		e.EndParagraph()
+	}
+}
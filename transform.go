@@ -0,0 +1,109 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/vec"
+
+	"seehuhn.de/go/pdf/graphics/content/builder"
+)
+
+// Rotate rotates the box counter-clockwise by the given angle (in degrees)
+// around the left end of its baseline.  The extent of the result is the
+// bounding box of the rotated box, shifted so that it starts at the
+// reference point.  The baseline of the result is the original baseline,
+// so a box rotated by 90 degrees stands on the baseline.
+func Rotate(angle float64, box Box) Box {
+	M := matrix.RotateDeg(angle)
+	for i := range 4 {
+		// avoid rounding errors for multiples of 90 degrees
+		if math.Abs(M[i]) < 1e-12 {
+			M[i] = 0
+		}
+	}
+	return &transformBox{box: box, M: M}
+}
+
+// Scale scales the box horizontally by sx and vertically by sy, relative
+// to the left end of its baseline.  Negative factors reflect the box; the
+// extent of the result is adjusted accordingly.
+func Scale(sx, sy float64, box Box) Box {
+	return &transformBox{box: box, M: matrix.Scale(sx, sy)}
+}
+
+// Reflect mirrors the box left to right.  The mirror image occupies the
+// same space as the original box.  Use Scale(1, -1, box) to turn a box
+// upside down.
+func Reflect(box Box) Box {
+	return &transformBox{box: box, M: matrix.Scale(-1, 1)}
+}
+
+// transformBox draws a box with the linear transformation M applied.
+type transformBox struct {
+	box Box
+	M   matrix.Matrix
+}
+
+// bounds returns the horizontal and vertical range covered by the
+// transformed box.
+func (obj *transformBox) bounds() (ext *BoxExtent, xMin, xMax, yMin, yMax float64) {
+	ext = obj.box.Extent()
+	corners := []vec.Vec2{
+		{X: 0, Y: -ext.Depth},
+		{X: ext.Width, Y: -ext.Depth},
+		{X: 0, Y: ext.Height},
+		{X: ext.Width, Y: ext.Height},
+	}
+	xMin, yMin = math.Inf(1), math.Inf(1)
+	xMax, yMax = math.Inf(-1), math.Inf(-1)
+	for _, c := range corners {
+		p := obj.M.Apply(c)
+		xMin = min(xMin, p.X)
+		xMax = max(xMax, p.X)
+		yMin = min(yMin, p.Y)
+		yMax = max(yMax, p.Y)
+	}
+	return ext, xMin, xMax, yMin, yMax
+}
+
+func (obj *transformBox) Extent() *BoxExtent {
+	ext, xMin, xMax, yMin, yMax := obj.bounds()
+	return &BoxExtent{
+		Width:          xMax - xMin,
+		Height:         yMax,
+		Depth:          -yMin,
+		WhiteSpaceOnly: ext.WhiteSpaceOnly,
+	}
+}
+
+func (obj *transformBox) Draw(page *builder.Builder, xPos, yPos float64) {
+	_, xMin, _, _, _ := obj.bounds()
+	M := obj.M
+	M[4] = xPos - xMin
+	M[5] = yPos
+	page.PushGraphicsState()
+	page.Transform(M)
+	obj.box.Draw(page, 0, 0)
+	page.PopGraphicsState()
+}
+
+func (obj *transformBox) children() []Box {
+	return []Box{obj.box}
+}
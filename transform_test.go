@@ -0,0 +1,47 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransformExtent(t *testing.T) {
+	inner := Rule(30, 10, 2)
+	cases := []struct {
+		box  Box
+		want BoxExtent
+	}{
+		{Rotate(0, inner), BoxExtent{Width: 30, Height: 10, Depth: 2}},
+		{Rotate(90, inner), BoxExtent{Width: 12, Height: 30, Depth: 0}},
+		{Rotate(180, inner), BoxExtent{Width: 30, Height: 2, Depth: 10}},
+		{Rotate(-90, inner), BoxExtent{Width: 12, Height: 0, Depth: 30}},
+		{Rotate(45, Rule(10, 10, 0)), BoxExtent{Width: 10 * math.Sqrt2, Height: 10 * math.Sqrt2, Depth: 0}},
+		{Scale(2, 0.5, inner), BoxExtent{Width: 60, Height: 5, Depth: 1}},
+		{Scale(1, -1, inner), BoxExtent{Width: 30, Height: 2, Depth: 10}},
+		{Reflect(inner), BoxExtent{Width: 30, Height: 10, Depth: 2}},
+	}
+	for i, c := range cases {
+		got := c.box.Extent()
+		if math.Abs(got.Width-c.want.Width) > 1e-9 ||
+			math.Abs(got.Height-c.want.Height) > 1e-9 ||
+			math.Abs(got.Depth-c.want.Depth) > 1e-9 {
+			t.Errorf("%d: got %v, want %v", i, got, &c.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrNotStable is returned by [CrossRefs.Run] if the page numbers of labels,
+// headings or index marks still change after the maximal number of layout
+// passes.
+var ErrNotStable = errors.New("cross-references did not stabilise")
+
+// CrossRefs collects the locations of labels, headings and index marks, so
+// that they can be referred to in a later layout pass.
+//
+// To use cross-references, the document is typeset several times, using a
+// new Engine for every pass.  The CrossRefs object is shared between the
+// passes, via the Engine.CrossRefs field.  References are resolved using the
+// locations from the previous pass.
+type CrossRefs struct {
+	prevLabels   map[string]int
+	prevHeadings []*Heading
+	prevIndex    []*indexMark
+
+	labels   map[string]int
+	headings []*Heading
+	index    []*indexMark
+}
+
+// Run calls layout repeatedly, until the page numbers of all labels,
+// headings and index marks are the same as in the previous pass.  Every call
+// of layout must typeset the complete document, using an Engine with
+// CrossRefs set to r.  If the page numbers have not stabilised after maxPasses calls,
+// [ErrNotStable] is returned.
+func (r *CrossRefs) Run(maxPasses int, layout func() error) error {
+	for range maxPasses {
+		r.labels = make(map[string]int)
+		r.headings = nil
+		r.index = nil
+
+		err := layout()
+		if err != nil {
+			return err
+		}
+
+		stable := r.isStable()
+		r.prevLabels, r.prevHeadings, r.prevIndex = r.labels, r.headings, r.index
+		if stable {
+			return nil
+		}
+	}
+	return ErrNotStable
+}
+
+// isStable reports whether the current pass has the same labels, headings and
+// index marks, on the same pages, as the previous pass.
+func (r *CrossRefs) isStable() bool {
+	if len(r.labels) != len(r.prevLabels) ||
+		len(r.headings) != len(r.prevHeadings) ||
+		len(r.index) != len(r.prevIndex) {
+		return false
+	}
+	for name, pageNo := range r.labels {
+		prev, ok := r.prevLabels[name]
+		if !ok || prev != pageNo {
+			return false
+		}
+	}
+	for i, h := range r.headings {
+		prev := r.prevHeadings[i]
+		if h.Level != prev.Level || h.Title != prev.Title || h.pageNo() != prev.pageNo() {
+			return false
+		}
+	}
+	for i, m := range r.index {
+		prev := r.prevIndex[i]
+		if m.IndexEntry != prev.IndexEntry || m.pageNo != prev.pageNo {
+			return false
+		}
+	}
+	return true
+}
+
+// VLabel attaches a label to the next box added in vertical mode.
+// The page number of the box can then be referred to using
+// [Engine.HAddPageRef].
+func (e *Engine) VLabel(name string) {
+	root := e.root()
+	e.VRecordNextBox(func(bi *BoxInfo) {
+		if root.labels == nil {
+			root.labels = make(map[string]int)
+		}
+		root.labels[name] = bi.PageNo
+		if r := e.CrossRefs; r != nil {
+			if r.labels == nil {
+				r.labels = make(map[string]int)
+			}
+			r.labels[name] = bi.PageNo
+		}
+	})
+}
+
+// LabelPage returns the page number of the box with the given label.
+// If CrossRefs is set, the page number from the previous layout pass is
+// used.  Otherwise, only labels on pages which have already been output can
+// be found.
+func (e *Engine) LabelPage(name string) (int, bool) {
+	var pageNo int
+	var ok bool
+	if e.CrossRefs != nil {
+		pageNo, ok = e.CrossRefs.prevLabels[name]
+	} else {
+		pageNo, ok = e.root().labels[name]
+	}
+	return pageNo, ok
+}
+
+// HAddPageRef adds the page number of the box with the given label to the
+// horizontal mode list.  If the label is not known, "??" is used instead.
+func (e *Engine) HAddPageRef(F *FontInfo, name string) {
+	e.HAddText(F, pageText(e.LabelPage(name)))
+}
+
+// pageText formats a page number for use in the text.
+func pageText(pageNo int, ok bool) string {
+	if !ok {
+		return "??"
+	}
+	return strconv.Itoa(pageNo)
+}
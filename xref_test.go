@@ -0,0 +1,83 @@
+// seehuhn.de/go/layout - a PDF layout engine
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package layout
+
+import (
+	"errors"
+	"testing"
+
+	"seehuhn.de/go/pdf/document"
+)
+
+func TestCrossRefs(t *testing.T) {
+	fi := testFont(t)
+
+	refs := &CrossRefs{}
+	var e *Engine
+	passes := 0
+	layout := func() error {
+		passes++
+		doc, _ := newTestDoc(t)
+
+		e = &Engine{
+			PageSize:     document.A4,
+			TextWidth:    300,
+			TextHeight:   50,
+			BaseLineSkip: 12,
+			CrossRefs:    refs,
+		}
+		e.VAddTableOfContents(fi, 10)
+		e.HAddText(fi, "see page ")
+		e.HAddPageRef(fi, "target")
+		e.EndParagraph()
+		for i := range 4 {
+			e.VAddPenalty(PenaltyForceBreak)
+			e.VMarkHeading(1+i%2, "Section")
+			if i == 2 {
+				e.VLabel("target")
+			}
+			e.VAddBox(Rule(10, 10, 0))
+		}
+
+		err := e.AppendPages(doc.Tree, doc.RM, true)
+		if err != nil {
+			return err
+		}
+		return doc.Close()
+	}
+
+	err := refs.Run(5, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 2 {
+		t.Errorf("expected 2 passes, got %d", passes)
+	}
+	if pageNo, ok := e.LabelPage("target"); !ok || pageNo != 4 {
+		t.Errorf("expected target on page 4, got %d", pageNo)
+	}
+
+	if n := len(refs.prevHeadings); n != 4 {
+		t.Errorf("expected 4 headings, got %d", n)
+	}
+
+	refs = &CrossRefs{}
+	err = refs.Run(1, layout)
+	if !errors.Is(err, ErrNotStable) {
+		t.Errorf("expected ErrNotStable, got %v", err)
+	}
+}